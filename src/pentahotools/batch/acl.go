@@ -0,0 +1,310 @@
+package batch
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/uphy/pentahotools/client"
+	"github.com/uphy/pentahotools/table"
+	"go.uber.org/zap"
+	"gopkg.in/cheggaaa/pb.v1"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// BatchACLClient is the API Client for exporting/applying ACLs.
+type BatchACLClient interface {
+	Tree(path string, depth int, showHidden bool) (*client.FileEntry, error)
+	GetACL(path string) (*client.ACL, error)
+	SetACL(path string, acl *client.ACL) error
+}
+
+// ACLDefinitions represents the ACLs file.
+type ACLDefinitions struct {
+	ACLs []ACLDefinition `yaml:"acls"`
+}
+
+// ACLDefinition represents the ACL of a file in the ACLs file.
+// Entries are omitted if the file inherits the entries from the parent folder.
+type ACLDefinition struct {
+	Path       string            `yaml:"path"`
+	Owner      string            `yaml:"owner,omitempty"`
+	Inheriting bool              `yaml:"inheriting"`
+	Entries    []EntryDefinition `yaml:"entries,omitempty"`
+}
+
+// EntryDefinition represents an access control entry in the ACLs file.
+// Type is 'user' or 'role'.
+type EntryDefinition struct {
+	Recipient   string   `yaml:"recipient"`
+	Type        string   `yaml:"type"`
+	Permissions []string `yaml:"permissions"`
+}
+
+// ApplyACLsOptions represents the options for ApplyACLs func.
+type ApplyACLsOptions struct {
+	DryRun bool
+}
+
+// ACLApplyResult represents the result of applying the ACL of a file.
+// Changes is empty if the ACL is already the same.
+type ACLApplyResult struct {
+	Path    string
+	Changes []string
+	Err     error
+}
+
+// ExportACLs exports the ACLs of the folders under the path, and the files which have their own entries.
+func ExportACLs(path string, file string, bclient BatchACLClient) (int, error) {
+	root, err := bclient.Tree(path, -1, true)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list the files")
+	}
+	var definitions ACLDefinitions
+	err = root.Walk(func(entry *client.FileEntry) error {
+		acl, err := bclient.GetACL(entry.File.Path)
+		if err != nil {
+			return errors.Wrap(err, "failed to get the ACL of "+entry.File.Path)
+		}
+		if !entry.File.IsFolder() && acl.IsInheriting() {
+			return nil
+		}
+		definitions.ACLs = append(definitions.ACLs, newACLDefinition(entry.File.Path, acl))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	data, err := yaml.Marshal(&definitions)
+	if err != nil {
+		return 0, err
+	}
+	return len(definitions.ACLs), ioutil.WriteFile(file, data, 0644)
+}
+
+func newACLDefinition(path string, acl *client.ACL) ACLDefinition {
+	d := ACLDefinition{
+		Path:       path,
+		Owner:      acl.Owner,
+		Inheriting: acl.IsInheriting(),
+	}
+	if d.Inheriting {
+		// the entries are inherited from the parent folder.
+		return d
+	}
+	for _, ac := range acl.Aces {
+		if ac.Modifiable == "false" {
+			// managed by the server. (e.g., Administrator role)
+			continue
+		}
+		d.Entries = append(d.Entries, EntryDefinition{ac.Recipient, client.RecipientTypeName(ac.RecipientType), client.PermissionNames(ac.Permissions)})
+	}
+	return d
+}
+
+// ApplyACLs changes the ACLs of the files to the ones in the file.
+// The files not in the file are not changed.
+func ApplyACLs(file string, options *ApplyACLsOptions, bclient BatchACLClient, logger client.Logger) ([]ACLApplyResult, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var definitions ACLDefinitions
+	if err := yaml.Unmarshal(data, &definitions); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the ACLs file")
+	}
+	var results []ACLApplyResult
+	var hasError bool
+	for _, d := range definitions.ACLs {
+		changes, err := applyACL(&d, options, bclient)
+		if err != nil {
+			hasError = true
+			logger.Error("Failed to apply the ACL.", zap.String("path", d.Path), zap.Error(err))
+		}
+		results = append(results, ACLApplyResult{d.Path, changes, err})
+	}
+	if hasError {
+		return results, errors.New("failed to apply some ACLs")
+	}
+	return results, nil
+}
+
+func applyACL(d *ACLDefinition, options *ApplyACLsOptions, bclient BatchACLClient) ([]string, error) {
+	acl, err := bclient.GetACL(d.Path)
+	if err != nil {
+		return nil, err
+	}
+	desired, err := d.newACL(acl)
+	if err != nil {
+		return nil, err
+	}
+	changes := diffACL(acl, desired)
+	if len(changes) == 0 || options.DryRun {
+		return changes, nil
+	}
+	return changes, bclient.SetACL(d.Path, desired)
+}
+
+// newACL creates the ACL to set based on the current ACL.
+// The entries managed by the server are kept.
+func (d *ACLDefinition) newACL(current *client.ACL) (*client.ACL, error) {
+	acl := &client.ACL{
+		ID:        current.ID,
+		Owner:     current.Owner,
+		OwnerType: current.OwnerType,
+	}
+	if d.Owner != "" {
+		acl.Owner = d.Owner
+	}
+	acl.SetInheriting(d.Inheriting)
+	if d.Inheriting {
+		return acl, nil
+	}
+	for _, ac := range current.Aces {
+		if ac.Modifiable == "false" {
+			acl.Aces = append(acl.Aces, ac)
+		}
+	}
+	for _, e := range d.Entries {
+		var recipientType string
+		switch e.Type {
+		case "user":
+			recipientType = client.RecipientTypeUser
+		case "role":
+			recipientType = client.RecipientTypeRole
+		default:
+			return nil, fmt.Errorf("unsupported recipient type: %s (user/role)", e.Type)
+		}
+		permissions, err := client.ParsePermissions(strings.Join(e.Permissions, ","))
+		if err != nil {
+			return nil, err
+		}
+		acl.Grant(e.Recipient, recipientType, permissions)
+	}
+	return acl, nil
+}
+
+// diffACL describes the differences between the ACLs.
+func diffACL(current *client.ACL, desired *client.ACL) []string {
+	var changes []string
+	if current.Owner != desired.Owner {
+		changes = append(changes, fmt.Sprintf("owner %s -> %s", current.Owner, desired.Owner))
+	}
+	if current.IsInheriting() != desired.IsInheriting() {
+		changes = append(changes, fmt.Sprintf("inheriting %v -> %v", current.IsInheriting(), desired.IsInheriting()))
+	}
+	if desired.IsInheriting() {
+		return changes
+	}
+	entries := func(acl *client.ACL) map[string][]string {
+		m := map[string][]string{}
+		for _, ac := range acl.Aces {
+			m[client.RecipientTypeName(ac.RecipientType)+" "+ac.Recipient] = client.PermissionNames(ac.Permissions)
+		}
+		return m
+	}
+	currentEntries, desiredEntries := entries(current), entries(desired)
+	var recipients []string
+	for recipient := range currentEntries {
+		recipients = append(recipients, recipient)
+	}
+	for recipient := range desiredEntries {
+		if _, exists := currentEntries[recipient]; !exists {
+			recipients = append(recipients, recipient)
+		}
+	}
+	sort.Strings(recipients)
+	for _, recipient := range recipients {
+		c, inCurrent := currentEntries[recipient]
+		d, inDesired := desiredEntries[recipient]
+		switch {
+		case !inCurrent:
+			changes = append(changes, fmt.Sprintf("+ %s: %s", recipient, strings.Join(d, ",")))
+		case !inDesired:
+			changes = append(changes, fmt.Sprintf("- %s: %s", recipient, strings.Join(c, ",")))
+		case !reflect.DeepEqual(c, d):
+			changes = append(changes, fmt.Sprintf("~ %s: %s -> %s", recipient, strings.Join(c, ","), strings.Join(d, ",")))
+		}
+	}
+	return changes
+}
+
+// BatchACLReportClient is the API Client for reporting ACLs.
+type BatchACLReportClient interface {
+	BatchACLClient
+	ListUsersInRole(role string) (*[]string, error)
+}
+
+// ReportACLsOptions represents the options for ReportACLs func.
+type ReportACLsOptions struct {
+	// ExpandRoles adds the rows for the users in the roles.
+	ExpandRoles bool
+	// IncludeFiles adds the rows for the files which have their own entries.
+	IncludeFiles bool
+}
+
+// ReportACLs writes the effective permissions of the folders under the path to the file.
+// A row is written for each folder and recipient.
+func ReportACLs(path string, file string, options *ReportACLsOptions, bar *pb.ProgressBar, bclient BatchACLReportClient, logger client.Logger) error {
+	bar.Prefix("List files")
+	root, err := bclient.Tree(path, -1, true)
+	if err != nil {
+		return errors.Wrap(err, "failed to list the files")
+	}
+	var entries []*client.FileEntry
+	root.Walk(func(entry *client.FileEntry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	bar.Total = int64(len(entries))
+
+	writer, err := table.NewWriter(file, map[int]string{})
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+	writer.WriteHeader(&[]string{"Path", "Type", "Owner", "Recipient", "Recipient Type", "Permissions", "Inherited", "Via Role"})
+	usersInRole := map[string][]string{}
+	for _, entry := range entries {
+		bar.Prefix("ACL: " + entry.File.Path)
+		acl, err := bclient.GetACL(entry.File.Path)
+		if err != nil {
+			return errors.Wrap(err, "failed to get the ACL of "+entry.File.Path)
+		}
+		fileType := "folder"
+		if !entry.File.IsFolder() {
+			if !options.IncludeFiles || acl.IsInheriting() {
+				bar.Increment()
+				continue
+			}
+			fileType = "file"
+		}
+		inherited := fmt.Sprint(acl.IsInheriting())
+		for _, ac := range acl.Aces {
+			permissions := strings.Join(client.PermissionNames(ac.Permissions), ",")
+			recipientType := client.RecipientTypeName(ac.RecipientType)
+			writer.WriteRow(&[]string{entry.File.Path, fileType, acl.Owner, ac.Recipient, recipientType, permissions, inherited, ""})
+			if !options.ExpandRoles || ac.RecipientType != client.RecipientTypeRole {
+				continue
+			}
+			users, cached := usersInRole[ac.Recipient]
+			if !cached {
+				list, err := bclient.ListUsersInRole(ac.Recipient)
+				if err != nil {
+					logger.Warn("Failed to list users in role.", zap.String("role", ac.Recipient), zap.Error(err))
+				} else if list != nil {
+					users = *list
+				}
+				usersInRole[ac.Recipient] = users
+			}
+			for _, user := range users {
+				writer.WriteRow(&[]string{entry.File.Path, fileType, acl.Owner, user, "user", permissions, inherited, ac.Recipient})
+			}
+		}
+		bar.Increment()
+	}
+	return nil
+}
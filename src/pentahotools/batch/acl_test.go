@@ -0,0 +1,172 @@
+package batch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/uphy/pentahotools/client"
+	"github.com/uphy/pentahotools/table"
+	"gopkg.in/cheggaaa/pb.v1"
+)
+
+func TestApplyACLDefinition(t *testing.T) {
+	current := &client.ACL{
+		ID:                "1",
+		Owner:             "admin",
+		OwnerType:         client.RecipientTypeUser,
+		EntriesInheriting: "true",
+		Aces: client.AcList{
+			{Modifiable: "false", Permissions: []string{client.PermissionAll}, Recipient: "Administrator", RecipientType: client.RecipientTypeRole},
+			{Modifiable: "true", Permissions: []string{client.PermissionRead}, Recipient: "Authenticated", RecipientType: client.RecipientTypeRole},
+			{Modifiable: "true", Permissions: []string{client.PermissionAll}, Recipient: "joe", RecipientType: client.RecipientTypeUser},
+		},
+	}
+	d := &ACLDefinition{
+		Path: "/public/sales",
+		Entries: []EntryDefinition{
+			{"Authenticated", "role", []string{"read"}},
+			{"Sales", "role", []string{"read", "write"}},
+		},
+	}
+	desired, err := d.newACL(current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desired.IsInheriting() || desired.Owner != "admin" || len(desired.Aces) != 3 {
+		t.Errorf("unexpected acl: %v", desired)
+	}
+	expected := []string{
+		"inheriting true -> false",
+		"+ role Sales: read,write",
+		"- user joe: all",
+	}
+	if changes := diffACL(current, desired); !reflect.DeepEqual(changes, expected) {
+		t.Errorf("expected %v but %v", expected, changes)
+	}
+
+	exported := newACLDefinition("/public/sales", desired)
+	if !reflect.DeepEqual(exported.Entries, d.Entries) {
+		t.Errorf("expected %v but %v", d.Entries, exported.Entries)
+	}
+	if changes := diffACL(desired, desired); len(changes) != 0 {
+		t.Errorf("unexpected changes: %v", changes)
+	}
+}
+
+// reportClient is BatchACLReportClient which has the fixed files and ACLs.
+type reportClient struct {
+	BatchACLReportClient
+	tree        *client.FileEntry
+	acls        map[string]*client.ACL
+	roles       map[string][]string
+	roleQueries int
+}
+
+func (c *reportClient) Tree(path string, depth int, showHidden bool) (*client.FileEntry, error) {
+	return c.tree, nil
+}
+
+func (c *reportClient) GetACL(path string) (*client.ACL, error) {
+	return c.acls[path], nil
+}
+
+func (c *reportClient) ListUsersInRole(role string) (*[]string, error) {
+	c.roleQueries++
+	users := c.roles[role]
+	return &users, nil
+}
+
+func TestReportACLs(t *testing.T) {
+	folder := func(path string, children ...client.FileEntry) client.FileEntry {
+		return client.FileEntry{File: client.FileInfo{Path: path, Folder: "true"}, Children: children}
+	}
+	file := func(path string) client.FileEntry {
+		return client.FileEntry{File: client.FileInfo{Path: path, Folder: "false"}}
+	}
+	root := folder("/public/sales",
+		folder("/public/sales/q1"),
+		file("/public/sales/report.prpt"),
+		file("/public/sales/sales.ktr"),
+	)
+	explicit := &client.ACL{Owner: "admin", EntriesInheriting: "false", Aces: client.AcList{
+		{Permissions: []string{client.PermissionRead}, Recipient: "Sales", RecipientType: client.RecipientTypeRole},
+		{Permissions: []string{client.PermissionRead, client.PermissionWrite}, Recipient: "joe", RecipientType: client.RecipientTypeUser},
+	}}
+	inheriting := &client.ACL{Owner: "admin", EntriesInheriting: "true", Aces: explicit.Aces}
+	bclient := &reportClient{
+		tree: &root,
+		acls: map[string]*client.ACL{
+			"/public/sales":             explicit,
+			"/public/sales/q1":          inheriting,
+			"/public/sales/report.prpt": explicit,
+			"/public/sales/sales.ktr":   inheriting,
+		},
+		roles: map[string][]string{"Sales": {"amy", "bob"}},
+	}
+
+	dir, err := ioutil.TempDir("", "report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, c := range []struct {
+		options  ReportACLsOptions
+		expected []string
+	}{
+		{
+			options: ReportACLsOptions{},
+			expected: []string{
+				"/public/sales folder admin Sales role read false ",
+				"/public/sales folder admin joe user read,write false ",
+				"/public/sales/q1 folder admin Sales role read true ",
+				"/public/sales/q1 folder admin joe user read,write true ",
+			},
+		},
+		{
+			options: ReportACLsOptions{ExpandRoles: true, IncludeFiles: true},
+			expected: []string{
+				"/public/sales folder admin Sales role read false ",
+				"/public/sales folder admin amy user read false Sales",
+				"/public/sales folder admin bob user read false Sales",
+				"/public/sales folder admin joe user read,write false ",
+				"/public/sales/q1 folder admin Sales role read true ",
+				"/public/sales/q1 folder admin amy user read true Sales",
+				"/public/sales/q1 folder admin bob user read true Sales",
+				"/public/sales/q1 folder admin joe user read,write true ",
+				"/public/sales/report.prpt file admin Sales role read false ",
+				"/public/sales/report.prpt file admin amy user read false Sales",
+				"/public/sales/report.prpt file admin bob user read false Sales",
+				"/public/sales/report.prpt file admin joe user read,write false ",
+			},
+		},
+	} {
+		output := filepath.Join(dir, "report.csv")
+		bclient.roleQueries = 0
+		if err := ReportACLs("/public/sales", output, &c.options, pb.New(0), bclient, client.NewCompositeLogger()); err != nil {
+			t.Fatal(err)
+		}
+		reader, err := table.NewReader(output, map[int]string{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rows []string
+		row := make([]string, 8)
+		for reader.ReadRow(&row) {
+			rows = append(rows, strings.Join(row, " "))
+		}
+		reader.Close()
+		if len(rows) == 0 || !strings.HasPrefix(rows[0], "Path ") {
+			t.Fatalf("the header is not written: %v", rows)
+		}
+		if !reflect.DeepEqual(rows[1:], c.expected) {
+			t.Errorf("expected %v but %v", c.expected, rows[1:])
+		}
+		if c.options.ExpandRoles && bclient.roleQueries != 1 {
+			t.Errorf("the users in the role should be listed once: %d", bclient.roleQueries)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package batch
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/uphy/pentahotools/client"
+	"go.uber.org/zap"
+)
+
+// BatchBackupClient is the API Client for backup.
+type BatchBackupClient interface {
+	Backup(output string) error
+}
+
+// backupTimeFormat is the format of the timestamp in the backup file name.
+const backupTimeFormat = "20060102-150405"
+
+// backupFilePattern matches the names of the backup files created in a directory.
+var backupFilePattern = regexp.MustCompile(`^backup-(\d{8}-\d{6})\.zip$`)
+
+// BackupOptions represents the options for Backup func.
+// The retention policy is applied only if the output is a directory.
+type BackupOptions struct {
+	// Keep is the number of the latest backups to keep.
+	Keep int
+	// KeepDaily is the number of the days to keep the latest backup of the day.
+	KeepDaily int
+	// KeepWeekly is the number of the weeks to keep the latest backup of the week.
+	KeepWeekly int
+}
+
+func (o *BackupOptions) hasRetention() bool {
+	return o.Keep > 0 || o.KeepDaily > 0 || o.KeepWeekly > 0
+}
+
+// BackupResult represents the result of Backup func.
+type BackupResult struct {
+	File string
+	// Entries is the number of the files in the backup.
+	Entries int
+	// Deleted is the old backup files deleted by the retention policy.
+	Deleted []string
+}
+
+// Backup downloads the backup and verifies it.
+// If the output is a directory, the backup is saved with the timestamped name and the old backups are deleted by the retention policy.
+// The backup is saved to the output only if the verification succeeded.
+func Backup(output string, options *BackupOptions, bclient BatchBackupClient, logger client.Logger) (*BackupResult, error) {
+	dir := ""
+	if info, err := os.Stat(output); (err == nil && info.IsDir()) || strings.HasSuffix(output, "/") {
+		dir = output
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+		output = filepath.Join(dir, "backup-"+time.Now().Format(backupTimeFormat)+".zip")
+	} else if options.hasRetention() {
+		return nil, errors.New("specify a directory as the output to keep the backups")
+	}
+
+	tmpFile := output + ".part"
+	defer os.Remove(tmpFile)
+	if err := bclient.Backup(tmpFile); err != nil {
+		return nil, err
+	}
+	entries, err := VerifyBackup(tmpFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "the downloaded backup is broken")
+	}
+	if err := os.Rename(tmpFile, output); err != nil {
+		return nil, err
+	}
+	result := &BackupResult{File: output, Entries: entries}
+	if dir == "" || !options.hasRetention() {
+		return result, nil
+	}
+
+	expired, err := expiredBackups(dir, options)
+	if err != nil {
+		return result, err
+	}
+	for _, file := range expired {
+		if err := os.Remove(file); err != nil {
+			logger.Warn("Failed to delete the old backup.", zap.String("file", file), zap.Error(err))
+			continue
+		}
+		result.Deleted = append(result.Deleted, file)
+	}
+	return result, nil
+}
+
+// VerifyBackup checks that the backup zip can be read to the end and contains the manifest.
+// It returns the number of the files in the backup.
+func VerifyBackup(file string) (int, error) {
+	archive, err := zip.OpenReader(file)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to open the zip")
+	}
+	defer archive.Close()
+	hasManifest := false
+	for _, f := range archive.File {
+		r, err := f.Open()
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to open "+f.Name)
+		}
+		if f.Name == manifestFileName {
+			hasManifest = true
+			err = xml.NewDecoder(r).Decode(&struct{}{})
+			if err == nil {
+				// the checksum is verified at the end of the entry.
+				_, err = io.Copy(ioutil.Discard, r)
+			}
+		} else {
+			_, err = io.Copy(ioutil.Discard, r)
+		}
+		r.Close()
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to read "+f.Name)
+		}
+	}
+	if !hasManifest {
+		return 0, errors.New(manifestFileName + " not found")
+	}
+	return len(archive.File), nil
+}
+
+// expiredBackups lists the backups in the directory which are not kept by the retention policy.
+// The latest backup of each day/week is kept for the latest days/weeks like the rotation of the logs.
+func expiredBackups(dir string, options *BackupOptions) ([]string, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	type backup struct {
+		file string
+		time time.Time
+	}
+	var backups []backup
+	for _, f := range files {
+		m := backupFilePattern.FindStringSubmatch(f.Name())
+		if m == nil || f.IsDir() {
+			continue
+		}
+		t, err := time.ParseInLocation(backupTimeFormat, m[1], time.Local)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{filepath.Join(dir, f.Name()), t})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].time.After(backups[j].time)
+	})
+
+	keep := map[string]bool{}
+	for i := 0; i < options.Keep && i < len(backups); i++ {
+		keep[backups[i].file] = true
+	}
+	keepLatest := func(n int, period func(t time.Time) string) {
+		periods := map[string]bool{}
+		for _, b := range backups {
+			if len(periods) >= n {
+				return
+			}
+			p := period(b.time)
+			if !periods[p] {
+				periods[p] = true
+				keep[b.file] = true
+			}
+		}
+	}
+	keepLatest(options.KeepDaily, func(t time.Time) string {
+		return t.Format("2006-01-02")
+	})
+	keepLatest(options.KeepWeekly, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-%02d", year, week)
+	})
+
+	var expired []string
+	for _, b := range backups {
+		if !keep[b.file] {
+			expired = append(expired, b.file)
+		}
+	}
+	return expired, nil
+}
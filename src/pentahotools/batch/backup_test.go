@@ -0,0 +1,156 @@
+package batch
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/uphy/pentahotools/client"
+)
+
+func writeTestZip(t *testing.T, file string, names ...string) {
+	out, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	w := zip.NewWriter(out)
+	for _, name := range names {
+		f, _ := w.Create(name)
+		if name == manifestFileName {
+			f.Write([]byte("<ExportManifest></ExportManifest>"))
+		} else {
+			f.Write([]byte(name))
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyBackup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	valid := filepath.Join(dir, "valid.zip")
+	writeTestZip(t, valid, manifestFileName, "public/a.ktr")
+	if entries, err := VerifyBackup(valid); err != nil || entries != 2 {
+		t.Errorf("expected valid backup, got %d, %v", entries, err)
+	}
+	noManifest := filepath.Join(dir, "nomanifest.zip")
+	writeTestZip(t, noManifest, "public/a.ktr")
+	if _, err := VerifyBackup(noManifest); err == nil {
+		t.Error("expected error for the backup without the manifest")
+	}
+	data, _ := ioutil.ReadFile(valid)
+	truncated := filepath.Join(dir, "truncated.zip")
+	ioutil.WriteFile(truncated, data[:len(data)/2], 0644)
+	if _, err := VerifyBackup(truncated); err == nil {
+		t.Error("expected error for the truncated backup")
+	}
+}
+
+func TestExpiredBackups(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// daily backups for 3 weeks, and the additional backup in the last day.
+	last := time.Date(2017, 7, 23, 1, 0, 0, 0, time.Local) // Sunday
+	var names []string
+	for i := 0; i < 21; i++ {
+		names = append(names, "backup-"+last.AddDate(0, 0, -i).Format(backupTimeFormat)+".zip")
+	}
+	names = append(names, "backup-"+last.Add(-time.Hour).Format(backupTimeFormat)+".zip", "other.zip")
+	for _, name := range names {
+		ioutil.WriteFile(filepath.Join(dir, name), nil, 0644)
+	}
+	kept := func(options *BackupOptions) []string {
+		expired, err := expiredBackups(dir, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var kept []string
+		for _, name := range names {
+			found := false
+			for _, file := range expired {
+				if file == filepath.Join(dir, name) {
+					found = true
+				}
+			}
+			if !found && name != "other.zip" {
+				kept = append(kept, name)
+			}
+		}
+		sort.Strings(kept)
+		return kept
+	}
+	if actual, expected := kept(&BackupOptions{Keep: 2}), []string{"backup-20170723-000000.zip", "backup-20170723-010000.zip"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+	if actual, expected := kept(&BackupOptions{KeepDaily: 2}), []string{"backup-20170722-010000.zip", "backup-20170723-010000.zip"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+	// the latest backups of the weeks ending on Sunday.
+	if actual, expected := kept(&BackupOptions{KeepWeekly: 3}), []string{"backup-20170709-010000.zip", "backup-20170716-010000.zip", "backup-20170723-010000.zip"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+	if actual, expected := kept(&BackupOptions{Keep: 1, KeepDaily: 2, KeepWeekly: 2}), []string{"backup-20170716-010000.zip", "backup-20170722-010000.zip", "backup-20170723-010000.zip"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+// backupClientFunc is BatchBackupClient which writes the backup by the function.
+type backupClientFunc func(output string) error
+
+func (f backupClientFunc) Backup(output string) error {
+	return f(output)
+}
+
+func TestBackup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	logger := client.NewCompositeLogger()
+	bclient := backupClientFunc(func(output string) error {
+		writeTestZip(t, output, manifestFileName, "public/a.ktr")
+		return nil
+	})
+	old := filepath.Join(dir, "backup-20170101-000000.zip")
+	ioutil.WriteFile(old, nil, 0644)
+	result, err := Backup(dir, &BackupOptions{Keep: 1}, bclient, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(result.File) != dir || !backupFilePattern.MatchString(filepath.Base(result.File)) || result.Entries != 2 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if !reflect.DeepEqual(result.Deleted, []string{old}) {
+		t.Errorf("expected %s to be deleted, got %v", old, result.Deleted)
+	}
+
+	broken := backupClientFunc(func(output string) error {
+		return ioutil.WriteFile(output, []byte("truncated"), 0644)
+	})
+	file := filepath.Join(dir, "broken.zip")
+	if _, err := Backup(file, &BackupOptions{}, broken, logger); err == nil {
+		t.Error("expected error for the broken backup")
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("the broken backup should not be saved: %v", err)
+	}
+	if _, err := Backup(file, &BackupOptions{Keep: 1}, bclient, logger); err == nil {
+		t.Error("expected error for the retention with the file output")
+	}
+}
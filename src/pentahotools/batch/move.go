@@ -0,0 +1,155 @@
+package batch
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/uphy/pentahotools/client"
+)
+
+// BatchMoveClient is the API Client for moving/copying/renaming files.
+type BatchMoveClient interface {
+	GetFileInfo(repositoryPath string) (*client.FileInfo, error)
+	CreateDirectory(path string) error
+	MoveFiles(destinationFolder string, repositoryPaths ...string) error
+	CopyFiles(destinationFolder string, overwrite bool, repositoryPaths ...string) error
+	RenameFile(repositoryPath string, newName string) error
+	DeleteFiles(repositoryPaths ...string) error
+	DeleteFilesPermanently(repositoryPaths ...string) error
+}
+
+// TransferOptions represents the options for TransferFiles func.
+type TransferOptions struct {
+	// Copy copies the files instead of moving.
+	Copy bool
+	// Overwrite overwrites the existing files.  The overwritten files are moved to the trash unless copying into the folder.
+	Overwrite bool
+}
+
+// FileTransfer represents a file moved/copied/renamed.
+type FileTransfer struct {
+	Source string
+	Target string
+}
+
+// TransferFiles moves or copies the files.
+// The files are transferred into the destination if it is a folder, otherwise the single file is transferred and renamed to the destination.
+// The transfers done are returned even if it failed in the middle.
+func TransferFiles(sources []string, destination string, options *TransferOptions, bclient BatchMoveClient) ([]FileTransfer, error) {
+	destination = path.Clean(destination)
+	destinationInfo, err := bclient.GetFileInfo(destination)
+	if err != nil {
+		return nil, err
+	}
+	folder, newName := destination, ""
+	if destinationInfo == nil || !destinationInfo.IsFolder() {
+		if len(sources) > 1 {
+			return nil, errors.New("destination folder not found: " + destination)
+		}
+		folder, newName = path.Dir(destination), path.Base(destination)
+	}
+	var transfers []FileTransfer
+	for _, source := range sources {
+		source = path.Clean(source)
+		target, err := transferFile(source, folder, newName, options, bclient)
+		if err != nil {
+			return transfers, err
+		}
+		transfers = append(transfers, FileTransfer{source, target})
+	}
+	return transfers, nil
+}
+
+func transferFile(source string, folder string, newName string, options *TransferOptions, bclient BatchMoveClient) (string, error) {
+	name := path.Base(source)
+	if newName == "" {
+		newName = name
+	}
+	target := path.Join(folder, newName)
+	if target == source {
+		return "", errors.New("the source and the destination are the same: " + source)
+	}
+	if !options.Copy && folder == path.Dir(source) {
+		// moving in the same folder is renaming.
+		return RenameFile(source, newName, options.Overwrite, bclient)
+	}
+	if options.Copy && newName == name {
+		// the copy API overwrites the files.
+		if info, err := bclient.GetFileInfo(target); err != nil {
+			return "", err
+		} else if info != nil && !options.Overwrite {
+			return "", errors.New("the file already exists (use --overwrite): " + target)
+		}
+	} else if err := prepareTarget(target, options.Overwrite, bclient); err != nil {
+		return "", err
+	}
+
+	if newName == name {
+		if options.Copy {
+			return target, bclient.CopyFiles(folder, options.Overwrite, source)
+		}
+		return target, bclient.MoveFiles(folder, source)
+	}
+	return target, transferAndRename(source, folder, newName, options.Copy, bclient)
+}
+
+// transferAndRename transfers the file to the temporary folder in the destination folder, renames it, and then moves it to the destination folder.
+// The file can not be renamed in the destination folder directly because it may have the file with the same name as the source. (e.g., copying in the same folder)
+func transferAndRename(source string, folder string, newName string, isCopy bool, bclient BatchMoveClient) error {
+	tmpFolder := path.Join(folder, fmt.Sprintf(".transfer-%d", time.Now().UnixNano()))
+	if err := bclient.CreateDirectory(tmpFolder); err != nil {
+		return errors.Wrap(err, "failed to create the temporary folder")
+	}
+	err := func() error {
+		var err error
+		if isCopy {
+			err = bclient.CopyFiles(tmpFolder, false, source)
+		} else {
+			err = bclient.MoveFiles(tmpFolder, source)
+		}
+		if err != nil {
+			return err
+		}
+		if err := bclient.RenameFile(path.Join(tmpFolder, path.Base(source)), newName); err != nil {
+			return err
+		}
+		return bclient.MoveFiles(folder, path.Join(tmpFolder, newName))
+	}()
+	if err != nil {
+		if !isCopy {
+			return errors.Wrap(err, "failed to move the file, it may be left in "+tmpFolder)
+		}
+		bclient.DeleteFilesPermanently(tmpFolder)
+		return err
+	}
+	return bclient.DeleteFilesPermanently(tmpFolder)
+}
+
+// RenameFile renames the file/folder and returns the new path.
+// The existing file is moved to the trash if overwrite is true.
+func RenameFile(source string, newName string, overwrite bool, bclient BatchMoveClient) (string, error) {
+	source = path.Clean(source)
+	target := path.Join(path.Dir(source), newName)
+	if target == source {
+		return "", errors.New("the source and the destination are the same: " + source)
+	}
+	if err := prepareTarget(target, overwrite, bclient); err != nil {
+		return "", err
+	}
+	return target, bclient.RenameFile(source, newName)
+}
+
+// prepareTarget checks the target path of moving/copying/renaming the file.
+// The existing file is moved to the trash if overwrite is true.
+func prepareTarget(target string, overwrite bool, bclient BatchMoveClient) error {
+	info, err := bclient.GetFileInfo(target)
+	if err != nil || info == nil {
+		return err
+	}
+	if !overwrite {
+		return errors.New("the file already exists (use --overwrite): " + target)
+	}
+	return bclient.DeleteFiles(target)
+}
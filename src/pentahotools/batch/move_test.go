@@ -0,0 +1,293 @@
+package batch
+
+import (
+	"errors"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/uphy/pentahotools/client"
+)
+
+// fakeRepository is BatchMoveClient on the in-memory repository.
+// The files map has the paths of the files and the folders. (true for the folders)
+type fakeRepository struct {
+	files   map[string]bool
+	trashed []string
+}
+
+func newFakeRepository(paths ...string) *fakeRepository {
+	r := &fakeRepository{files: map[string]bool{"/": true}}
+	for _, p := range paths {
+		isFolder := strings.HasSuffix(p, "/")
+		p = path.Clean(p)
+		for dir := path.Dir(p); dir != "/"; dir = path.Dir(dir) {
+			r.files[dir] = true
+		}
+		r.files[p] = isFolder
+	}
+	return r
+}
+
+func (r *fakeRepository) list() []string {
+	var paths []string
+	for p := range r.files {
+		if p != "/" {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (r *fakeRepository) GetFileInfo(repositoryPath string) (*client.FileInfo, error) {
+	isFolder, exists := r.files[repositoryPath]
+	if !exists {
+		return nil, nil
+	}
+	folder := "false"
+	if isFolder {
+		folder = "true"
+	}
+	return &client.FileInfo{Path: repositoryPath, Name: path.Base(repositoryPath), Folder: folder}, nil
+}
+
+func (r *fakeRepository) CreateDirectory(p string) error {
+	if _, exists := r.files[p]; exists {
+		return errors.New("Path already exists")
+	}
+	r.files[p] = true
+	return nil
+}
+
+// transfer copies the file and its descendants into the folder.
+func (r *fakeRepository) transfer(destinationFolder string, repositoryPaths []string, remove bool) error {
+	if !r.files[destinationFolder] {
+		return errors.New("destination folder not found: " + destinationFolder)
+	}
+	for _, source := range repositoryPaths {
+		if _, exists := r.files[source]; !exists {
+			return errors.New("file not found: " + source)
+		}
+		target := path.Join(destinationFolder, path.Base(source))
+		if _, exists := r.files[target]; exists {
+			return errors.New("the file with the same name exists: " + target)
+		}
+		for p, isFolder := range r.files {
+			if p == source || strings.HasPrefix(p, source+"/") {
+				r.files[target+strings.TrimPrefix(p, source)] = isFolder
+				if remove {
+					delete(r.files, p)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (r *fakeRepository) MoveFiles(destinationFolder string, repositoryPaths ...string) error {
+	return r.transfer(destinationFolder, repositoryPaths, true)
+}
+
+func (r *fakeRepository) CopyFiles(destinationFolder string, overwrite bool, repositoryPaths ...string) error {
+	if overwrite {
+		for _, source := range repositoryPaths {
+			r.remove(path.Join(destinationFolder, path.Base(source)))
+		}
+	}
+	return r.transfer(destinationFolder, repositoryPaths, false)
+}
+
+func (r *fakeRepository) RenameFile(repositoryPath string, newName string) error {
+	target := path.Join(path.Dir(repositoryPath), newName)
+	if _, exists := r.files[target]; exists {
+		return errors.New("the file with the same name exists: " + target)
+	}
+	tmp := "/.rename"
+	r.files[tmp] = true
+	if err := r.transfer(tmp, []string{repositoryPath}, true); err != nil {
+		return err
+	}
+	for p, isFolder := range r.files {
+		if strings.HasPrefix(p, tmp+"/") {
+			delete(r.files, p)
+			r.files[target+strings.TrimPrefix(p, path.Join(tmp, path.Base(repositoryPath)))] = isFolder
+		}
+	}
+	delete(r.files, tmp)
+	return nil
+}
+
+func (r *fakeRepository) remove(p string) {
+	for f := range r.files {
+		if f == p || strings.HasPrefix(f, p+"/") {
+			delete(r.files, f)
+		}
+	}
+}
+
+func (r *fakeRepository) DeleteFiles(repositoryPaths ...string) error {
+	for _, p := range repositoryPaths {
+		r.remove(p)
+		r.trashed = append(r.trashed, p)
+	}
+	return nil
+}
+
+func (r *fakeRepository) DeleteFilesPermanently(repositoryPaths ...string) error {
+	for _, p := range repositoryPaths {
+		r.remove(p)
+	}
+	return nil
+}
+
+func TestTransferFiles(t *testing.T) {
+	for _, c := range []struct {
+		name        string
+		files       []string
+		sources     []string
+		destination string
+		options     TransferOptions
+		expected    []string
+		transfers   []FileTransfer
+		trashed     []string
+		err         bool
+	}{
+		{
+			name:        "move into folder",
+			files:       []string{"/public/a.ktr", "/public/b.ktr", "/home/"},
+			sources:     []string{"/public/a.ktr", "/public/b.ktr"},
+			destination: "/home",
+			expected:    []string{"/home", "/home/a.ktr", "/home/b.ktr", "/public"},
+			transfers:   []FileTransfer{{"/public/a.ktr", "/home/a.ktr"}, {"/public/b.ktr", "/home/b.ktr"}},
+		},
+		{
+			name:        "move folder with descendants",
+			files:       []string{"/public/sales/a.ktr", "/home/"},
+			sources:     []string{"/public/sales"},
+			destination: "/home/",
+			expected:    []string{"/home", "/home/sales", "/home/sales/a.ktr", "/public"},
+			transfers:   []FileTransfer{{"/public/sales", "/home/sales"}},
+		},
+		{
+			name:        "move in same folder is rename",
+			files:       []string{"/public/a.ktr"},
+			sources:     []string{"/public/a.ktr"},
+			destination: "/public/b.ktr",
+			expected:    []string{"/public", "/public/b.ktr"},
+			transfers:   []FileTransfer{{"/public/a.ktr", "/public/b.ktr"}},
+		},
+		{
+			name:        "move and rename",
+			files:       []string{"/public/a.ktr", "/home/a.ktr"},
+			sources:     []string{"/public/a.ktr"},
+			destination: "/home/b.ktr",
+			expected:    []string{"/home", "/home/a.ktr", "/home/b.ktr", "/public"},
+			transfers:   []FileTransfer{{"/public/a.ktr", "/home/b.ktr"}},
+		},
+		{
+			name:        "copy with new name in same folder",
+			files:       []string{"/public/a.ktr"},
+			sources:     []string{"/public/a.ktr"},
+			destination: "/public/b.ktr",
+			options:     TransferOptions{Copy: true},
+			expected:    []string{"/public", "/public/a.ktr", "/public/b.ktr"},
+			transfers:   []FileTransfer{{"/public/a.ktr", "/public/b.ktr"}},
+		},
+		{
+			name:        "copy into folder",
+			files:       []string{"/public/a.ktr", "/home/"},
+			sources:     []string{"/public/a.ktr"},
+			destination: "/home",
+			options:     TransferOptions{Copy: true},
+			expected:    []string{"/home", "/home/a.ktr", "/public", "/public/a.ktr"},
+			transfers:   []FileTransfer{{"/public/a.ktr", "/home/a.ktr"}},
+		},
+		{
+			name:        "copy to existing file",
+			files:       []string{"/public/a.ktr", "/home/a.ktr"},
+			sources:     []string{"/public/a.ktr"},
+			destination: "/home",
+			options:     TransferOptions{Copy: true},
+			expected:    []string{"/home", "/home/a.ktr", "/public", "/public/a.ktr"},
+			err:         true,
+		},
+		{
+			name:        "copy and overwrite",
+			files:       []string{"/public/a.ktr", "/home/a.ktr"},
+			sources:     []string{"/public/a.ktr"},
+			destination: "/home",
+			options:     TransferOptions{Copy: true, Overwrite: true},
+			expected:    []string{"/home", "/home/a.ktr", "/public", "/public/a.ktr"},
+			transfers:   []FileTransfer{{"/public/a.ktr", "/home/a.ktr"}},
+		},
+		{
+			name:        "move and overwrite",
+			files:       []string{"/public/a.ktr", "/home/b.ktr"},
+			sources:     []string{"/public/a.ktr"},
+			destination: "/home/b.ktr",
+			options:     TransferOptions{Overwrite: true},
+			expected:    []string{"/home", "/home/b.ktr", "/public"},
+			transfers:   []FileTransfer{{"/public/a.ktr", "/home/b.ktr"}},
+			trashed:     []string{"/home/b.ktr"},
+		},
+		{
+			name:        "move to existing file",
+			files:       []string{"/public/a.ktr", "/home/b.ktr"},
+			sources:     []string{"/public/a.ktr"},
+			destination: "/home/b.ktr",
+			expected:    []string{"/home", "/home/b.ktr", "/public", "/public/a.ktr"},
+			err:         true,
+		},
+		{
+			name:        "multiple files to missing folder",
+			files:       []string{"/public/a.ktr", "/public/b.ktr"},
+			sources:     []string{"/public/a.ktr", "/public/b.ktr"},
+			destination: "/home",
+			expected:    []string{"/public", "/public/a.ktr", "/public/b.ktr"},
+			err:         true,
+		},
+		{
+			name:        "same source and destination",
+			files:       []string{"/public/a.ktr"},
+			sources:     []string{"/public/a.ktr"},
+			destination: "/public",
+			expected:    []string{"/public", "/public/a.ktr"},
+			err:         true,
+		},
+	} {
+		r := newFakeRepository(c.files...)
+		transfers, err := TransferFiles(c.sources, c.destination, &c.options, r)
+		if (err != nil) != c.err {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+		}
+		if !reflect.DeepEqual(transfers, c.transfers) {
+			t.Errorf("%s: expected transfers %v, got %v", c.name, c.transfers, transfers)
+		}
+		if actual := r.list(); !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, actual)
+		}
+		if !reflect.DeepEqual(r.trashed, c.trashed) {
+			t.Errorf("%s: expected trashed %v, got %v", c.name, c.trashed, r.trashed)
+		}
+	}
+}
+
+func TestRenameFile(t *testing.T) {
+	r := newFakeRepository("/public/a.ktr", "/public/b.ktr")
+	if _, err := RenameFile("/public/a.ktr", "b.ktr", false, r); err == nil {
+		t.Error("expected error for the existing file")
+	}
+	target, err := RenameFile("/public/a.ktr", "b.ktr", true, r)
+	if err != nil || target != "/public/b.ktr" {
+		t.Errorf("unexpected result: %s, %v", target, err)
+	}
+	if expected := []string{"/public", "/public/b.ktr"}; !reflect.DeepEqual(r.list(), expected) {
+		t.Errorf("expected %v, got %v", expected, r.list())
+	}
+	if expected := []string{"/public/b.ktr"}; !reflect.DeepEqual(r.trashed, expected) {
+		t.Errorf("expected trashed %v, got %v", expected, r.trashed)
+	}
+}
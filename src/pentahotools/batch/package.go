@@ -0,0 +1,246 @@
+package batch
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/uphy/pentahotools/client"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// manifestPermissions are the names of the permissions in the export manifest.
+var manifestPermissions = map[string]string{
+	client.PermissionRead:   "READ",
+	client.PermissionWrite:  "WRITE",
+	client.PermissionDelete: "DELETE",
+	client.PermissionManage: "ACL_MANAGEMENT",
+	client.PermissionAll:    "ALL",
+}
+
+// PackageOptions represents the options for PackageDirectory func.
+type PackageOptions struct {
+	// ImportDir is the repository folder where the package is imported.
+	ImportDir string
+	// ACLFile is the YAML file written by ExportACLs.  The ACLs of the files under ImportDir are written to the manifest.
+	// The manifest is not generated if empty.
+	ACLFile string
+	// User is the owner of the files whose owner is not specified in ACLFile.
+	User string
+}
+
+// manifest is the exportManifest.xml in the import zip.
+type manifest struct {
+	XMLName     xml.Name         `xml:"ExportManifest"`
+	Information manifestInfo     `xml:"ExportManifestInformation"`
+	Entities    []manifestEntity `xml:"ExportManifestEntity"`
+}
+
+type manifestInfo struct {
+	ExportBy   string `xml:"exportBy,attr"`
+	ExportDate string `xml:"exportDate,attr"`
+	RootFolder string `xml:"rootFolder,attr"`
+}
+
+// manifestEntity has the properties of the metadata and the ACL in this order.
+type manifestEntity struct {
+	Path       string             `xml:"path,attr"`
+	Properties []manifestProperty `xml:"ExportManifestProperty"`
+}
+
+type manifestProperty struct {
+	MetaData *manifestMetaData  `xml:"EntityMetaData,omitempty"`
+	ACL      *manifestEntityACL `xml:"EntityAcl,omitempty"`
+}
+
+type manifestMetaData struct {
+	Name          string `xml:"name"`
+	IsHidden      bool   `xml:"isHidden"`
+	IsSchedulable bool   `xml:"isSchedulable"`
+	IsFolder      bool   `xml:"isFolder"`
+	Path          string `xml:"path"`
+	Title         string `xml:"title"`
+}
+
+type manifestEntityACL struct {
+	Aces              []manifestAce `xml:"aces"`
+	EntriesInheriting bool          `xml:"entriesInheriting"`
+	Owner             string        `xml:"owner"`
+	OwnerType         string        `xml:"ownerType"`
+}
+
+type manifestAce struct {
+	Recipient     string   `xml:"recipient"`
+	RecipientType string   `xml:"recipientType"`
+	Permissions   []string `xml:"permissions"`
+	Modifiable    bool     `xml:"modifiable"`
+}
+
+// PackageDirectory writes the files in the local directory to the zip file to import into the repository.
+// The files and directories starting with '.' (e.g., '.git') are skipped.
+// It returns the number of the packaged files.
+func PackageDirectory(dir string, zipFile string, options *PackageOptions) (int, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return 0, err
+	}
+	if !info.IsDir() {
+		return 0, errors.New("not a directory: " + dir)
+	}
+	out, err := os.Create(zipFile)
+	if err != nil {
+		return 0, err
+	}
+	defer out.Close()
+	w := zip.NewWriter(out)
+
+	count := 0
+	folders := map[string]bool{}
+	err = filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil || rel == "." {
+			return err
+		}
+		if strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if info.IsDir() {
+			folders[rel] = true
+			_, err := w.Create(rel + "/")
+			return err
+		}
+		count++
+		return addZipFile(w, file, rel)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if options.ACLFile != "" {
+		data, err := newManifest(options, folders)
+		if err != nil {
+			return 0, err
+		}
+		mw, err := w.Create(manifestFileName)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := mw.Write(data); err != nil {
+			return 0, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func addZipFile(w *zip.Writer, file string, name string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	zf, err := w.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(zf, f)
+	return err
+}
+
+// newManifest generates the export manifest with the ACLs in the ACL file.
+// The ACLs of the files out of the import directory are ignored.
+func newManifest(options *PackageOptions, folders map[string]bool) ([]byte, error) {
+	data, err := ioutil.ReadFile(options.ACLFile)
+	if err != nil {
+		return nil, err
+	}
+	var definitions ACLDefinitions
+	if err := yaml.Unmarshal(data, &definitions); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the ACLs file")
+	}
+	importDir := path.Clean("/" + options.ImportDir)
+	m := manifest{Information: manifestInfo{
+		ExportBy:   options.User,
+		ExportDate: time.Now().Format("02-01-2006 15:04:05 MST"),
+		RootFolder: strings.TrimSuffix(importDir, "/") + "/",
+	}}
+	for _, d := range definitions.ACLs {
+		p := path.Clean("/" + d.Path)
+		if !strings.HasPrefix(p, m.Information.RootFolder) {
+			continue
+		}
+		rel := strings.TrimPrefix(p, m.Information.RootFolder)
+		entity, err := d.newManifestEntity(rel, folders[rel], options.User)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid ACL of "+d.Path)
+		}
+		m.Entities = append(m.Entities, entity)
+	}
+	sort.Slice(m.Entities, func(i, j int) bool {
+		return m.Entities[i].Path < m.Entities[j].Path
+	})
+	out, err := xml.MarshalIndent(&m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+func (d *ACLDefinition) newManifestEntity(rel string, isFolder bool, user string) (manifestEntity, error) {
+	name := path.Base(rel)
+	acl := &manifestEntityACL{
+		EntriesInheriting: d.Inheriting,
+		Owner:             d.Owner,
+		OwnerType:         "USER",
+	}
+	if acl.Owner == "" {
+		acl.Owner = user
+	}
+	entity := manifestEntity{
+		Path: rel,
+		Properties: []manifestProperty{
+			{MetaData: &manifestMetaData{Name: name, IsSchedulable: true, IsFolder: isFolder, Path: rel, Title: name}},
+			{ACL: acl},
+		},
+	}
+	if d.Inheriting {
+		return entity, nil
+	}
+	for _, e := range d.Entries {
+		var recipientType string
+		switch e.Type {
+		case "user":
+			recipientType = "USER"
+		case "role":
+			recipientType = "ROLE"
+		default:
+			return entity, errors.New("unsupported recipient type: " + e.Type + " (user/role)")
+		}
+		permissions, err := client.ParsePermissions(strings.Join(e.Permissions, ","))
+		if err != nil {
+			return entity, err
+		}
+		ace := manifestAce{Recipient: e.Recipient, RecipientType: recipientType, Modifiable: true}
+		for _, p := range permissions {
+			ace.Permissions = append(ace.Permissions, manifestPermissions[p])
+		}
+		acl.Aces = append(acl.Aces, ace)
+	}
+	return entity, nil
+}
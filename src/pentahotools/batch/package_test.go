@@ -0,0 +1,209 @@
+package batch
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// exportManifest is the manifest downloaded with 'file download --manifest /public/sales'.
+const exportManifest = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<ExportManifest>
+    <ExportManifestInformation exportBy="admin" exportDate="19-10-2017 10:00:00 JST" rootFolder="/public/"/>
+    <ExportManifestEntity path="sales">
+        <ExportManifestProperty>
+            <EntityMetaData>
+                <name>sales</name>
+                <createdDate>2017-10-19T10:00:00.000+09:00</createdDate>
+                <description></description>
+                <isHidden>false</isHidden>
+                <isSchedulable>true</isSchedulable>
+                <isFolder>true</isFolder>
+                <locale>en</locale>
+                <path>sales</path>
+                <title>sales</title>
+            </EntityMetaData>
+        </ExportManifestProperty>
+        <ExportManifestProperty>
+            <EntityAcl>
+                <aces>
+                    <modifiable>true</modifiable>
+                    <permissions>READ</permissions>
+                    <permissions>WRITE</permissions>
+                    <recipient>Authenticated</recipient>
+                    <recipientType>ROLE</recipientType>
+                </aces>
+                <entriesInheriting>false</entriesInheriting>
+                <owner>suzy</owner>
+                <ownerType>USER</ownerType>
+            </EntityAcl>
+        </ExportManifestProperty>
+    </ExportManifestEntity>
+</ExportManifest>`
+
+// xmlLeaves flattens the XML document into the paths of the elements and the attributes with the values.
+// (e.g., "ExportManifestEntity/ExportManifestProperty/EntityMetaData/name=sales", "ExportManifestEntity/@path=sales")
+func xmlLeaves(t *testing.T, data []byte) map[string]bool {
+	leaves := map[string]bool{}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var stack []string
+	var text string
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch tok := token.(type) {
+		case xml.StartElement:
+			stack = append(stack, tok.Name.Local)
+			for _, attr := range tok.Attr {
+				leaves[strings.Join(stack, "/")+"/@"+attr.Name.Local+"="+attr.Value] = true
+			}
+			text = ""
+		case xml.CharData:
+			text += string(tok)
+		case xml.EndElement:
+			if text = strings.TrimSpace(text); text != "" {
+				leaves[strings.Join(stack, "/")+"="+text] = true
+			}
+			stack = stack[:len(stack)-1]
+			text = ""
+		}
+	}
+	return leaves
+}
+
+func TestManifestFormat(t *testing.T) {
+	// the structs read the real manifest.
+	var m manifest
+	if err := xml.Unmarshal([]byte(exportManifest), &m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Entities) != 1 || len(m.Entities[0].Properties) != 2 {
+		t.Fatalf("unexpected manifest: %+v", m)
+	}
+	metaData, acl := m.Entities[0].Properties[0].MetaData, m.Entities[0].Properties[1].ACL
+	if metaData == nil || metaData.Name != "sales" || metaData.Path != "sales" || !metaData.IsFolder || !metaData.IsSchedulable {
+		t.Errorf("unexpected metadata: %+v", metaData)
+	}
+	if acl == nil || acl.Owner != "suzy" || len(acl.Aces) != 1 || !reflect.DeepEqual(acl.Aces[0].Permissions, []string{"READ", "WRITE"}) {
+		t.Errorf("unexpected acl: %+v", acl)
+	}
+
+	// the generated entity has the same elements as the real manifest.
+	d := ACLDefinition{
+		Path:  "/public/sales",
+		Owner: "suzy",
+		Entries: []EntryDefinition{
+			{"Authenticated", "role", []string{"read", "write"}},
+		},
+	}
+	entity, err := d.newManifestEntity("sales", true, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	generated, err := xml.Marshal(&manifest{
+		Information: manifestInfo{ExportBy: "admin", ExportDate: "19-10-2017 10:00:00 JST", RootFolder: "/public/"},
+		Entities:    []manifestEntity{entity},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := xmlLeaves(t, []byte(exportManifest))
+	for leaf := range xmlLeaves(t, generated) {
+		if !expected[leaf] {
+			t.Errorf("not in the real manifest: %s", leaf)
+		}
+	}
+}
+
+func TestPackageDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "package")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "src")
+	for _, file := range []string{"a.ktr", "sub/b.kjb", ".git/config"} {
+		p := filepath.Join(src, filepath.FromSlash(file))
+		os.MkdirAll(filepath.Dir(p), 0755)
+		if err := ioutil.WriteFile(p, []byte(file), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	aclFile := filepath.Join(dir, "acl.yml")
+	ioutil.WriteFile(aclFile, []byte(`acls:
+- path: /public/target/sub
+  owner: suzy
+  inheriting: false
+  entries:
+  - recipient: Authenticated
+    type: role
+    permissions: [read, write]
+- path: /public/target/a.ktr
+  inheriting: true
+- path: /public/other
+  inheriting: true
+`), 0644)
+
+	zipFile := filepath.Join(dir, "out.zip")
+	count, err := PackageDirectory(src, zipFile, &PackageOptions{ImportDir: "/public/target", ACLFile: aclFile, User: "admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 files, got %d", count)
+	}
+	archive, err := zip.OpenReader(zipFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+	var names []string
+	var m manifest
+	for _, f := range archive.File {
+		names = append(names, f.Name)
+		if f.Name != manifestFileName {
+			continue
+		}
+		r, _ := f.Open()
+		data, _ := ioutil.ReadAll(r)
+		r.Close()
+		if err := xml.Unmarshal(data, &m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sort.Strings(names)
+	if expected := []string{"a.ktr", manifestFileName, "sub/", "sub/b.kjb"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+	if m.Information.RootFolder != "/public/target/" || len(m.Entities) != 2 {
+		t.Fatalf("unexpected manifest: %+v", m)
+	}
+	a, sub := m.Entities[0], m.Entities[1]
+	if len(a.Properties) != 2 || len(sub.Properties) != 2 {
+		t.Fatalf("unexpected entities: %+v", m.Entities)
+	}
+	if a.Path != "a.ktr" || a.Properties[0].MetaData.IsFolder || !a.Properties[1].ACL.EntriesInheriting || a.Properties[1].ACL.Owner != "admin" {
+		t.Errorf("unexpected entity: %+v", a)
+	}
+	subACL := sub.Properties[1].ACL
+	if sub.Path != "sub" || !sub.Properties[0].MetaData.IsFolder || subACL.EntriesInheriting || subACL.Owner != "suzy" {
+		t.Errorf("unexpected entity: %+v", sub)
+	}
+	expectedAces := []manifestAce{{"Authenticated", "ROLE", []string{"READ", "WRITE"}, true}}
+	if !reflect.DeepEqual(subACL.Aces, expectedAces) {
+		t.Errorf("expected %v, got %v", expectedAces, subACL.Aces)
+	}
+}
@@ -0,0 +1,340 @@
+package batch
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/uphy/pentahotools/client"
+	"go.uber.org/zap"
+)
+
+// manifestFileName is the name of the manifest in the downloaded zip.
+const manifestFileName = "exportManifest.xml"
+
+// xmlFileExtensions are the extensions of the XML content which is pretty-printed by PullFiles.
+var xmlFileExtensions = []string{".ktr", ".kjb", ".xml", ".xaction", ".xanalyzer", ".xmi", ".xdash", ".prpti"}
+
+// The actions of PullChange.
+const (
+	PullActionCreate = "create"
+	PullActionUpdate = "update"
+	PullActionDelete = "delete"
+)
+
+// PullOptions represents the options for PullFiles func.
+type PullOptions struct {
+	// Delete deletes the local files which no longer exist in the repository.
+	// The files and directories starting with '.' (e.g., '.git') are kept.
+	Delete bool
+	// PrettyPrint indents the XML content.
+	PrettyPrint bool
+	// Manifest is the file to save the export manifest.  The manifest is not downloaded if empty.
+	Manifest string
+}
+
+// PullChange represents a change of the local file made by PullFiles.
+type PullChange struct {
+	Action    string
+	LocalPath string
+}
+
+// PullFiles downloads the repository directory and extracts it into the local directory.
+// The local files which have the same content are not rewritten to keep the timestamps.
+func PullFiles(remoteDir string, localDir string, options *PullOptions, bclient BatchFileClient, logger client.Logger) ([]PullChange, error) {
+	remoteDir = path.Clean("/" + remoteDir)
+	tmpFile, err := ioutil.TempFile("", "pull")
+	if err != nil {
+		return nil, errors.Wrap(err, "create temp file failed")
+	}
+	tmpFile.Close()
+	defer os.Remove(tmpFile.Name())
+	if _, err := bclient.DownloadFile(remoteDir, tmpFile.Name(), options.Manifest != "", true); err != nil {
+		return nil, errors.Wrap(err, "failed to download "+remoteDir)
+	}
+
+	archive, err := zip.OpenReader(tmpFile.Name())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open the downloaded zip")
+	}
+	defer archive.Close()
+
+	var changes []PullChange
+	extracted := map[string]bool{}
+	prefix := commonZipPrefix(archive.File, path.Base(remoteDir))
+	for _, f := range archive.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if f.Name == manifestFileName {
+			if options.Manifest == "" {
+				continue
+			}
+			if _, err := extractZipFile(f, options.Manifest, options.PrettyPrint, logger); err != nil {
+				return changes, err
+			}
+			continue
+		}
+		rel := strings.TrimPrefix(f.Name, prefix)
+		if clean := path.Clean(rel); rel == "" || clean == ".." || strings.HasPrefix(clean, "../") || path.IsAbs(rel) {
+			return changes, errors.New("invalid file path in the zip: " + f.Name)
+		}
+		localPath := filepath.Join(localDir, filepath.FromSlash(path.Clean(rel)))
+		extracted[localPath] = true
+		action, err := extractZipFile(f, localPath, options.PrettyPrint, logger)
+		if err != nil {
+			return changes, err
+		}
+		if action != "" {
+			changes = append(changes, PullChange{action, localPath})
+		}
+	}
+	if !options.Delete {
+		return changes, nil
+	}
+
+	if options.Manifest != "" {
+		// keep the manifest saved in the local directory.
+		if rel, err := relativePath(localDir, options.Manifest); err == nil {
+			extracted[filepath.Join(localDir, rel)] = true
+		}
+	}
+	var deleting []string
+	err = filepath.Walk(localDir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if file == localDir {
+			return nil
+		}
+		if strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			if !hasExtractedFile(extracted, file) {
+				deleting = append(deleting, file)
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !extracted[file] {
+			deleting = append(deleting, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return changes, err
+	}
+	sort.Strings(deleting)
+	for _, file := range deleting {
+		if err := os.RemoveAll(file); err != nil {
+			return changes, err
+		}
+		changes = append(changes, PullChange{PullActionDelete, file})
+	}
+	return changes, nil
+}
+
+// relativePath gets the relative path of the file in the directory.
+// It returns an error if the file is not in the directory.
+func relativePath(dir string, file string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	absFile, err := filepath.Abs(file)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(absDir, absFile)
+	if err != nil {
+		return "", err
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.New("not in the directory: " + file)
+	}
+	return rel, nil
+}
+
+// commonZipPrefix gets the prefix of the zip entries to strip.
+// The entries of the downloaded folder are usually in the directory named after the folder.
+func commonZipPrefix(files []*zip.File, name string) string {
+	prefix := name + "/"
+	for _, f := range files {
+		if f.Name != manifestFileName && !strings.HasPrefix(f.Name, prefix) {
+			return ""
+		}
+	}
+	return prefix
+}
+
+// hasExtractedFile checks if any extracted file is in the directory.
+func hasExtractedFile(extracted map[string]bool, dir string) bool {
+	dir = dir + string(filepath.Separator)
+	for file := range extracted {
+		if strings.HasPrefix(file, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// extractZipFile writes the zip entry to the file.
+// It returns the action taken, or an empty string if the file is unchanged.
+func extractZipFile(f *zip.File, file string, prettyPrint bool, logger client.Logger) (string, error) {
+	r, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	data, err := ioutil.ReadAll(r)
+	r.Close()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read "+f.Name)
+	}
+	if prettyPrint && isXMLFile(f.Name) {
+		if formatted, err := prettyPrintXML(data); err == nil {
+			data = formatted
+		} else {
+			logger.Warn("Failed to pretty-print the XML file.", zap.String("file", f.Name), zap.Error(err))
+		}
+	}
+	action := PullActionCreate
+	if existing, err := ioutil.ReadFile(file); err == nil {
+		if bytes.Equal(existing, data) {
+			return "", nil
+		}
+		action = PullActionUpdate
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return "", err
+	}
+	return action, ioutil.WriteFile(file, data, 0644)
+}
+
+func isXMLFile(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	for _, e := range xmlFileExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// xmlTextEscaper escapes the character data minimally to keep the content readable.
+// CR is escaped because the XML parser normalizes the raw CRLF to LF. (e.g., the SQL in the .ktr)
+var xmlTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+
+// prettyPrintXML indents the XML document.
+// Only the whitespaces between the elements are replaced, so that the text content is kept even if it is whitespaces. (e.g., "<x>\n</x>")
+// It fails for the malformed XML, such as the undefined entities, which can not be written back as it is.
+func prettyPrintXML(data []byte) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var buf bytes.Buffer
+	level := 0
+	// pending is the start element which is not closed yet, to write the empty element as '<name/>'.
+	pending := false
+	// inline is true after the text content, so that the end element is written in the same line.
+	inline := false
+	newline := func() {
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(strings.Repeat("  ", level))
+	}
+	closePending := func() {
+		if pending {
+			buf.WriteString(">")
+			pending = false
+		}
+	}
+	// whitespace is the whitespace-only text, which is written only if it is the content of the element.
+	var whitespace []byte
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			whitespace = nil
+			closePending()
+			newline()
+			buf.WriteString("<" + xmlName(t.Name))
+			for _, attr := range t.Attr {
+				buf.WriteString(" " + xmlName(attr.Name) + `="`)
+				xml.EscapeText(&buf, []byte(attr.Value))
+				buf.WriteString(`"`)
+			}
+			pending = true
+			inline = false
+			level++
+		case xml.EndElement:
+			level--
+			if pending && whitespace != nil {
+				closePending()
+				buf.WriteString(xmlTextEscaper.Replace(string(whitespace)))
+				inline = true
+			}
+			whitespace = nil
+			if pending {
+				buf.WriteString("/>")
+				pending = false
+			} else {
+				if !inline {
+					newline()
+				}
+				buf.WriteString("</" + xmlName(t.Name) + ">")
+			}
+			inline = false
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) == 0 {
+				whitespace = append([]byte{}, t...)
+				continue
+			}
+			whitespace = nil
+			closePending()
+			buf.WriteString(xmlTextEscaper.Replace(string(t)))
+			inline = true
+		case xml.Comment:
+			whitespace = nil
+			closePending()
+			newline()
+			buf.WriteString("<!--" + string(t) + "-->")
+		case xml.ProcInst:
+			whitespace = nil
+			closePending()
+			newline()
+			buf.WriteString("<?" + t.Target + " " + string(t.Inst) + "?>")
+		case xml.Directive:
+			whitespace = nil
+			closePending()
+			newline()
+			buf.WriteString("<!" + string(t) + ">")
+		}
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// xmlName formats the raw name with the namespace prefix.
+func xmlName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
@@ -0,0 +1,195 @@
+package batch
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/uphy/pentahotools/client"
+)
+
+func TestPrettyPrintXML(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8"?><transformation><info><name>a &amp; b</name><description/><sql>SELECT *
+  FROM "t"</sql><separator> </separator></info><step x:id="1" xmlns:x="urn:x"><name>Step</name></step></transformation>`
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<transformation>
+  <info>
+    <name>a &amp; b</name>
+    <description/>
+    <sql>SELECT *
+  FROM "t"</sql>
+    <separator> </separator>
+  </info>
+  <step x:id="1" xmlns:x="urn:x">
+    <name>Step</name>
+  </step>
+</transformation>
+`
+	actual, err := prettyPrintXML([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(actual) != expected {
+		t.Errorf("expected\n%s\nbut\n%s", expected, actual)
+	}
+	// already formatted content is not changed.
+	again, err := prettyPrintXML(actual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != expected {
+		t.Errorf("expected\n%s\nbut\n%s", expected, again)
+	}
+}
+
+func TestPrettyPrintXMLWhitespaceContent(t *testing.T) {
+	for input, expected := range map[string]string{
+		"<a><x>\n</x><y> </y><z>\t\n </z></a>": "<a>\n  <x>\n</x>\n  <y> </y>\n  <z>\t\n </z>\n</a>\n",
+		"<a> <b/> \n <c/>\n</a>":               "<a>\n  <b/>\n  <c/>\n</a>\n",
+		"<a><b>text</b>\n</a>":                 "<a>\n  <b>text</b>\n</a>\n",
+		"<a><cr>&#xD;</cr></a>":                "<a>\n  <cr>&#xD;</cr>\n</a>\n",
+	} {
+		actual, err := prettyPrintXML([]byte(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(actual) != expected {
+			t.Errorf("%q: expected %q but %q", input, expected, actual)
+		}
+	}
+}
+
+// xmlContents gets the attributes and the character data of the XML document, except the whitespaces between the elements.
+func xmlContents(t *testing.T, data []byte) []string {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var contents []string
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return contents
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			for _, attr := range token.Attr {
+				contents = append(contents, token.Name.Local+"@"+attr.Name.Local+"="+attr.Value)
+			}
+		case xml.CharData:
+			if len(bytes.TrimSpace(token)) > 0 {
+				contents = append(contents, string(token))
+			}
+		}
+	}
+}
+
+func TestPrettyPrintXMLRoundTrip(t *testing.T) {
+	input := "<transformation><info><sql>SELECT a&#xd;\nFROM b&#xD;&#xA;</sql><text>&lt;&amp;&gt;\"'\ttab</text></info>" +
+		"<step name=\"line1&#xA;line2&#x9;tab&#xD;\" quote=\"&quot;'&lt;&amp;\"><name>Step</name></step></transformation>"
+	expected := xmlContents(t, []byte(input))
+	if expected[0] != "SELECT a\r\nFROM b\r\n" {
+		t.Fatalf("unexpected input: %q", expected[0])
+	}
+	actual, err := prettyPrintXML([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contents := xmlContents(t, actual); !reflect.DeepEqual(contents, expected) {
+		t.Errorf("expected %q but %q", expected, contents)
+	}
+	if _, err := prettyPrintXML([]byte("<a>&nbsp;</a>")); err == nil {
+		t.Error("expected error for the undefined entity")
+	}
+}
+
+// pullClient is BatchFileClient which downloads the zip with the files.
+type pullClient struct {
+	BatchFileClient
+	files map[string]string
+}
+
+func (c *pullClient) DownloadFile(repositoryPath string, destination string, withManifest bool, overwrite bool) (string, error) {
+	out, err := os.Create(destination)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+	w := zip.NewWriter(out)
+	for name, content := range c.files {
+		if name == manifestFileName && !withManifest {
+			continue
+		}
+		f, _ := w.Create(name)
+		f.Write([]byte(content))
+	}
+	return destination, w.Close()
+}
+
+func TestPullFilesDeleteKeepsManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pull")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, file := range []string{"a.ktr", "old.ktr", "meta/manifest.xml", ".git/config"} {
+		p := filepath.Join(dir, filepath.FromSlash(file))
+		os.MkdirAll(filepath.Dir(p), 0755)
+		ioutil.WriteFile(p, []byte("old"), 0644)
+	}
+	bclient := &pullClient{files: map[string]string{
+		"sales/a.ktr":    "<a/>",
+		manifestFileName: "<ExportManifest/>",
+	}}
+	options := &PullOptions{Delete: true, Manifest: filepath.Join(dir, "meta", "manifest.xml")}
+	changes, err := PullFiles("/public/sales", dir, options, bclient, client.NewCompositeLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []PullChange{
+		{PullActionUpdate, filepath.Join(dir, "a.ktr")},
+		{PullActionDelete, filepath.Join(dir, "old.ktr")},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("expected %v, got %v", expected, changes)
+	}
+	var remaining []string
+	filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if !info.IsDir() {
+			rel, _ := filepath.Rel(dir, file)
+			remaining = append(remaining, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	sort.Strings(remaining)
+	if expected := []string{".git/config", "a.ktr", "meta/manifest.xml"}; !reflect.DeepEqual(remaining, expected) {
+		t.Errorf("expected %v, got %v", expected, remaining)
+	}
+	if data, _ := ioutil.ReadFile(options.Manifest); string(data) != "<ExportManifest/>" {
+		t.Errorf("unexpected manifest: %s", data)
+	}
+}
+
+func TestPullFilesInvalidPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pull")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"sales/..", "sales/../a.ktr", "sales/b/../../a.ktr"} {
+		bclient := &pullClient{files: map[string]string{
+			"sales/a.ktr": "<a/>",
+			name:          "<b/>",
+		}}
+		if _, err := PullFiles("/public/sales", dir, &PullOptions{}, bclient, client.NewCompositeLogger()); err == nil {
+			t.Errorf("expected error for %s", name)
+		}
+	}
+}
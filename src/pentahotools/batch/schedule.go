@@ -0,0 +1,255 @@
+package batch
+
+import (
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/uphy/pentahotools/client"
+	"go.uber.org/zap"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// BatchScheduleClient is the API Client for exporting/importing schedules.
+type BatchScheduleClient interface {
+	ListJobs() ([]client.Job, error)
+	ScheduleJob(req *client.JobScheduleRequest) (string, error)
+	RemoveJob(jobID string) error
+	PauseJob(jobID string) (string, error)
+}
+
+// ScheduleDefinitions represents the schedules file.
+type ScheduleDefinitions struct {
+	Schedules []ScheduleDefinition `yaml:"schedules"`
+}
+
+// ScheduleDefinition represents a schedule in the schedules file.
+type ScheduleDefinition struct {
+	Name       string `yaml:"name"`
+	InputFile  string `yaml:"inputFile"`
+	OutputFile string `yaml:"outputFile,omitempty"`
+	// AppendDateFormat is the date format appended to the name of the generated content. (e.g., "yyyy-MM-dd")
+	AppendDateFormat string                `yaml:"appendDateFormat,omitempty"`
+	Owner            string                `yaml:"owner,omitempty"`
+	State            string                `yaml:"state,omitempty"`
+	Trigger          TriggerDefinition     `yaml:"trigger"`
+	Parameters       []ParameterDefinition `yaml:"parameters,omitempty"`
+}
+
+// ParameterDefinition represents a parameter of a schedule in the schedules file.
+type ParameterDefinition struct {
+	Name   string   `yaml:"name"`
+	Type   string   `yaml:"type"`
+	Values []string `yaml:"values"`
+}
+
+// TriggerDefinition represents the trigger of a schedule in the schedules file.
+// Specify either Cron or RepeatInterval.
+type TriggerDefinition struct {
+	Cron           string `yaml:"cron,omitempty"`
+	RepeatInterval int    `yaml:"repeatInterval,omitempty"`
+	RepeatCount    int    `yaml:"repeatCount,omitempty"`
+	StartTime      string `yaml:"startTime,omitempty"`
+	EndTime        string `yaml:"endTime,omitempty"`
+}
+
+// ImportSchedulesOptions represents the options for ImportSchedules func.
+type ImportSchedulesOptions struct {
+	DryRun  bool
+	Replace bool
+	// PathMapping rewrites the prefix of the input/output paths. (e.g., "/public/dev" -> "/public/prod")
+	PathMapping map[string]string
+}
+
+// ExportSchedules exports all of the schedules to the file.
+func ExportSchedules(file string, bclient BatchScheduleClient) (int, error) {
+	jobs, err := bclient.ListJobs()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list the schedules")
+	}
+	var definitions ScheduleDefinitions
+	for _, job := range jobs {
+		d, err := newScheduleDefinition(&job)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to export the schedule "+job.JobName)
+		}
+		definitions.Schedules = append(definitions.Schedules, d)
+	}
+	data, err := yaml.Marshal(&definitions)
+	if err != nil {
+		return 0, err
+	}
+	return len(definitions.Schedules), ioutil.WriteFile(file, data, 0644)
+}
+
+// newScheduleDefinition creates the definition of the job.
+// The complex trigger is exported as the cron expression, and it is an error if the cron expression is not available.
+func newScheduleDefinition(job *client.Job) (ScheduleDefinition, error) {
+	d := ScheduleDefinition{
+		Name:       job.JobName,
+		InputFile:  job.InputFile(),
+		OutputFile: job.OutputFile(),
+		Owner:      job.UserName,
+		State:      job.State,
+		Trigger: TriggerDefinition{
+			StartTime: job.JobTrigger.StartTime,
+			EndTime:   job.JobTrigger.EndTime,
+		},
+	}
+	if job.JobTrigger.Type == "simpleJobTrigger" {
+		d.Trigger.RepeatInterval = int(job.JobTrigger.RepeatInterval)
+		d.Trigger.RepeatCount = int(job.JobTrigger.RepeatCount)
+	} else if job.JobTrigger.CronString != "" {
+		d.Trigger.Cron = job.JobTrigger.CronString
+	} else {
+		return d, errors.New("unsupported trigger without the cron expression: " + job.JobTrigger.Type)
+	}
+	if dateFormat := job.JobParams.Get("appendDateFormat"); dateFormat != nil {
+		d.AppendDateFormat = *dateFormat
+	}
+	for _, param := range job.JobParams.JobParams {
+		if param.IsInternal() || param.Name == "appendDateFormat" {
+			continue
+		}
+		parameterType, value := param.TypedValue()
+		d.Parameters = append(d.Parameters, ParameterDefinition{param.Name, parameterType, []string{value}})
+	}
+	return d, nil
+}
+
+// ScheduleImportResult represents the result of importing a schedule.
+// Action is one of 'create', 'replace' or 'skip'.
+type ScheduleImportResult struct {
+	Name      string
+	InputFile string
+	Owner     string
+	Action    string
+	Err       error
+}
+
+// ImportSchedules recreates the schedules from the file.
+// The existing schedule which has the same name and input file is skipped, or replaced if options.Replace is true.
+func ImportSchedules(file string, options *ImportSchedulesOptions, bclient BatchScheduleClient, logger client.Logger) ([]ScheduleImportResult, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var definitions ScheduleDefinitions
+	if err := yaml.Unmarshal(data, &definitions); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the schedules file")
+	}
+	jobs, err := bclient.ListJobs()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the schedules")
+	}
+	var results []ScheduleImportResult
+	var hasError bool
+	for _, d := range definitions.Schedules {
+		d.InputFile = mapPath(d.InputFile, options.PathMapping)
+		d.OutputFile = mapPath(d.OutputFile, options.PathMapping)
+		action, err := importSchedule(&d, jobs, options, bclient)
+		if err != nil {
+			hasError = true
+			logger.Error("Failed to import the schedule.", zap.String("name", d.Name), zap.String("inputFile", d.InputFile), zap.String("err", err.Error()))
+		}
+		results = append(results, ScheduleImportResult{d.Name, d.InputFile, d.Owner, action, err})
+	}
+	if hasError {
+		return results, errors.New("Errors occured while importing schedules")
+	}
+	return results, nil
+}
+
+func importSchedule(d *ScheduleDefinition, jobs []client.Job, options *ImportSchedulesOptions, bclient BatchScheduleClient) (string, error) {
+	action := "create"
+	req := d.newJobScheduleRequest()
+	if err := req.Validate(); err != nil {
+		return action, err
+	}
+	var existingJobIDs []string
+	for _, job := range jobs {
+		if job.JobName == d.Name && job.InputFile() == d.InputFile {
+			existingJobIDs = append(existingJobIDs, job.JobID)
+		}
+	}
+	if len(existingJobIDs) > 0 {
+		if !options.Replace {
+			return "skip", nil
+		}
+		action = "replace"
+	}
+	if options.DryRun {
+		return action, nil
+	}
+	for _, jobID := range existingJobIDs {
+		if err := bclient.RemoveJob(jobID); err != nil {
+			return action, errors.Wrap(err, "failed to remove the existing schedule")
+		}
+	}
+	jobID, err := bclient.ScheduleJob(req)
+	if err != nil {
+		return action, err
+	}
+	if d.State == "PAUSED" {
+		if _, err := bclient.PauseJob(jobID); err != nil {
+			return action, errors.Wrap(err, "failed to pause the schedule")
+		}
+	}
+	return action, nil
+}
+
+func (d *ScheduleDefinition) newJobScheduleRequest() *client.JobScheduleRequest {
+	req := &client.JobScheduleRequest{
+		JobName:          d.Name,
+		InputFile:        d.InputFile,
+		OutputFile:       d.OutputFile,
+		AppendDateFormat: d.AppendDateFormat,
+	}
+	// the exported output file is the pattern of the generated content. (e.g., "/home/admin/report.*")
+	// the scheduler requires the folder and names the content after the job name.
+	if strings.HasSuffix(d.OutputFile, ".*") {
+		req.OutputFile = path.Dir(d.OutputFile)
+	}
+	if d.Trigger.Cron != "" {
+		req.CronJobTrigger = &client.CronJobTrigger{
+			UIPassParam: "CRON",
+			CronString:  d.Trigger.Cron,
+			StartTime:   d.Trigger.StartTime,
+			EndTime:     d.Trigger.EndTime,
+		}
+	} else {
+		req.SimpleJobTrigger = &client.SimpleJobTrigger{
+			RepeatInterval: d.Trigger.RepeatInterval,
+			RepeatCount:    d.Trigger.RepeatCount,
+			StartTime:      d.Trigger.StartTime,
+			EndTime:        d.Trigger.EndTime,
+		}
+	}
+	for _, p := range d.Parameters {
+		parameterType := p.Type
+		if parameterType == "" {
+			parameterType = "string"
+		}
+		req.JobParameters = append(req.JobParameters, client.JobParameter{Name: p.Name, Type: parameterType, StringValue: p.Values})
+	}
+	return req
+}
+
+// mapPath rewrites the prefix of the path by the longest matching mapping.
+func mapPath(path string, mapping map[string]string) string {
+	var prefixes []string
+	for prefix := range mapping {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool {
+		return len(prefixes[i]) > len(prefixes[j])
+	})
+	for _, prefix := range prefixes {
+		if path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/") {
+			return mapping[prefix] + strings.TrimPrefix(path, prefix)
+		}
+	}
+	return path
+}
@@ -0,0 +1,89 @@
+package batch
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/uphy/pentahotools/client"
+)
+
+func TestMapPath(t *testing.T) {
+	mapping := map[string]string{
+		"/public/dev":         "/public/prod",
+		"/public/dev/reports": "/public/reports",
+	}
+	for path, expected := range map[string]string{
+		"/public/dev/job.kjb":         "/public/prod/job.kjb",
+		"/public/dev/reports/a.prpt":  "/public/reports/a.prpt",
+		"/public/development/job.kjb": "/public/development/job.kjb",
+		"/home/admin/job.kjb":         "/home/admin/job.kjb",
+	} {
+		if actual := mapPath(path, mapping); actual != expected {
+			t.Errorf("expected %s but %s", expected, actual)
+		}
+	}
+}
+
+func TestNewScheduleDefinition(t *testing.T) {
+	job := client.Job{
+		JobName:  "daily",
+		UserName: "admin",
+		State:    "NORMAL",
+		JobParams: client.JobParams{JobParams: []client.JobParam{
+			{Name: "ActionAdapterQuartzJob-StreamProvider", Value: "input file = /public/report.prpt:outputFile = /home/admin/report.*"},
+			{Name: "uiPassParam", Value: "CRON"},
+			{Name: "appendDateFormat", Value: "yyyy-MM-dd"},
+			{Name: "region", Value: "east"},
+			{Name: "limit", Value: "100.0"},
+			{Name: "rate", Value: "-1.5E-3"},
+			{Name: "code", Value: "01234"},
+			{Name: "id", Value: "12345"},
+			{Name: "detail", Value: "false"},
+			{Name: "since", Value: "Mon Oct 02 02:00:00 UTC 2017"},
+			{Name: "version", Value: "1.0.0"},
+		}},
+		JobTrigger: client.JobTrigger{Type: "cronJobTrigger", CronString: "0 30 2 ? * MON-FRI"},
+	}
+	d, err := newScheduleDefinition(&job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.InputFile != "/public/report.prpt" || d.OutputFile != "/home/admin/report.*" || d.AppendDateFormat != "yyyy-MM-dd" {
+		t.Errorf("unexpected input/output: %s, %s, %s", d.InputFile, d.OutputFile, d.AppendDateFormat)
+	}
+	if d.Trigger.Cron != "0 30 2 ? * MON-FRI" {
+		t.Errorf("unexpected trigger: %v", d.Trigger)
+	}
+	expected := []ParameterDefinition{
+		{"region", "string", []string{"east"}},
+		{"limit", "number", []string{"100.0"}},
+		{"rate", "number", []string{"-1.5E-3"}},
+		{"code", "string", []string{"01234"}},
+		{"id", "string", []string{"12345"}},
+		{"detail", "boolean", []string{"false"}},
+		{"since", "date", []string{"2017-10-02T02:00:00.000+00:00"}},
+		{"version", "string", []string{"1.0.0"}},
+	}
+	if !reflect.DeepEqual(d.Parameters, expected) {
+		t.Errorf("expected %v but %v", expected, d.Parameters)
+	}
+	for _, p := range d.Parameters {
+		param := client.JobParameter{Name: p.Name, Type: p.Type, StringValue: p.Values}
+		if err := param.Validate(); err != nil {
+			t.Errorf("the exported parameter should be imported: %s", err)
+		}
+	}
+	req := d.newJobScheduleRequest()
+	if req.OutputFile != "/home/admin" || req.AppendDateFormat != "yyyy-MM-dd" || req.CronJobTrigger == nil || req.SimpleJobTrigger != nil {
+		t.Errorf("unexpected request: %v", req)
+	}
+
+	job.JobTrigger = client.JobTrigger{Type: "complexJobTrigger"}
+	if d, err := newScheduleDefinition(&job); err == nil {
+		t.Errorf("expected error for the complex trigger without the cron expression, got %v", d.Trigger)
+	}
+	job.JobTrigger = client.JobTrigger{Type: "complexJobTrigger", CronString: "0 0 3 ? * 2#1"}
+	if d, err := newScheduleDefinition(&job); err != nil || d.Trigger.Cron != "0 0 3 ? * 2#1" {
+		t.Errorf("unexpected trigger: %v, %v", d.Trigger, err)
+	}
+}
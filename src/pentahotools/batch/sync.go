@@ -0,0 +1,240 @@
+package batch
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/uphy/pentahotools/client"
+	"go.uber.org/zap"
+	"gopkg.in/cheggaaa/pb.v1"
+)
+
+// BatchFileClient is the API Client for synchronizing files.
+type BatchFileClient interface {
+	Tree(path string, depth int, showHidden bool) (*client.FileEntry, error)
+	CreateDirectory(path string) error
+	PutFile(file string, destination string) error
+	GetFileContent(repositoryPath string) ([]byte, error)
+	DownloadFile(repositoryPath string, destination string, withManifest bool, overwrite bool) (string, error)
+	DeleteFiles(repositoryPaths ...string) error
+}
+
+// The actions of SyncChange.
+const (
+	SyncActionCreateDirectory = "mkdir"
+	SyncActionUpload          = "upload"
+	SyncActionUpdate          = "update"
+	SyncActionDelete          = "delete"
+)
+
+// SyncOptions represents the options for SyncFiles func.
+type SyncOptions struct {
+	// Delete deletes the remote files which are missing locally.
+	Delete bool
+	DryRun bool
+	// Checksum compares the content hash instead of the size and the date.
+	Checksum bool
+	// Includes and Excludes are the glob patterns matched with the relative path or the name of the file. (e.g., "*.ktr", "tmp/*")
+	Includes []string
+	Excludes []string
+}
+
+// SyncChange represents a change made by SyncFiles.
+type SyncChange struct {
+	Action     string
+	LocalPath  string
+	RemotePath string
+	Err        error
+}
+
+// SyncFiles synchronizes the local directory tree into the repository directory.
+// The changes are returned even if the synchronization failed in the middle.
+func SyncFiles(localDir string, remoteDir string, options *SyncOptions, bar *pb.ProgressBar, bclient BatchFileClient, logger client.Logger) ([]SyncChange, error) {
+	remoteDir = path.Clean("/" + remoteDir)
+	bar.Prefix("List remote files")
+	remoteFiles := map[string]client.FileInfo{}
+	root, err := bclient.Tree(remoteDir, -1, true)
+	if err == client.ErrNotFound {
+		logger.Debug("The remote directory does not exist.", zap.String("remoteDir", remoteDir))
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to list the remote files")
+	} else {
+		root.Walk(func(entry *client.FileEntry) error {
+			remoteFiles[entry.File.Path] = entry.File
+			return nil
+		})
+	}
+
+	changes, err := planSync(localDir, remoteDir, remoteFiles, options, bclient)
+	if err != nil {
+		return nil, err
+	}
+	if options.DryRun {
+		return changes, nil
+	}
+
+	bar.Total = int64(len(changes))
+	var hasError bool
+	for i := range changes {
+		change := &changes[i]
+		bar.Prefix(change.Action + " " + change.RemotePath)
+		switch change.Action {
+		case SyncActionCreateDirectory:
+			change.Err = bclient.CreateDirectory(change.RemotePath)
+		case SyncActionUpload, SyncActionUpdate:
+			change.Err = bclient.PutFile(change.LocalPath, change.RemotePath)
+		case SyncActionDelete:
+			change.Err = bclient.DeleteFiles(change.RemotePath)
+		}
+		if change.Err != nil {
+			hasError = true
+			logger.Error("Failed to synchronize the file.", zap.String("action", change.Action), zap.String("remotePath", change.RemotePath), zap.Error(change.Err))
+			if change.Action == SyncActionCreateDirectory {
+				// the files in the directory can not be uploaded.
+				return changes, errors.Wrap(change.Err, "failed to create the directory "+change.RemotePath)
+			}
+		}
+		bar.Increment()
+	}
+	if hasError {
+		return changes, errors.New("failed to synchronize some files")
+	}
+	return changes, nil
+}
+
+// planSync compares the local tree with the remote files and lists the changes to make.
+func planSync(localDir string, remoteDir string, remoteFiles map[string]client.FileInfo, options *SyncOptions, bclient BatchFileClient) ([]SyncChange, error) {
+	var changes []SyncChange
+	if _, exists := remoteFiles[remoteDir]; !exists {
+		changes = append(changes, SyncChange{Action: SyncActionCreateDirectory, LocalPath: localDir, RemotePath: remoteDir})
+	}
+	localFiles := map[string]bool{remoteDir: true}
+	err := filepath.Walk(localDir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(localDir, file)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if !options.matches(rel, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		remotePath := path.Join(remoteDir, rel)
+		localFiles[remotePath] = true
+		remote, exists := remoteFiles[remotePath]
+		if info.IsDir() {
+			if !exists {
+				changes = append(changes, SyncChange{Action: SyncActionCreateDirectory, LocalPath: file, RemotePath: remotePath})
+			}
+			return nil
+		}
+		if !exists {
+			changes = append(changes, SyncChange{Action: SyncActionUpload, LocalPath: file, RemotePath: remotePath})
+			return nil
+		}
+		changed, err := isChanged(file, info, &remote, options.Checksum, bclient)
+		if err != nil {
+			return err
+		}
+		if changed {
+			changes = append(changes, SyncChange{Action: SyncActionUpdate, LocalPath: file, RemotePath: remotePath})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !options.Delete {
+		return changes, nil
+	}
+	var deleting []string
+	for remotePath, remote := range remoteFiles {
+		if localFiles[remotePath] || !strings.HasPrefix(remotePath, remoteDir+"/") {
+			continue
+		}
+		rel := strings.TrimPrefix(remotePath, remoteDir+"/")
+		if !options.matches(rel, remote.IsFolder()) || !localFiles[path.Dir(remotePath)] {
+			// the parent folder is deleted or out of the filters.
+			continue
+		}
+		deleting = append(deleting, remotePath)
+	}
+	sort.Strings(deleting)
+	for _, remotePath := range deleting {
+		changes = append(changes, SyncChange{Action: SyncActionDelete, RemotePath: remotePath})
+	}
+	return changes, nil
+}
+
+// matches checks if the relative path passes the include/exclude patterns.
+// The include patterns are not applied to the directories so that the files in them can be matched.
+func (o *SyncOptions) matches(rel string, isDir bool) bool {
+	for _, pattern := range o.Excludes {
+		if matchGlob(pattern, rel) {
+			return false
+		}
+	}
+	if isDir || len(o.Includes) == 0 {
+		return true
+	}
+	for _, pattern := range o.Includes {
+		if matchGlob(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches the glob pattern with the slash separated path or its last element.
+func matchGlob(pattern string, p string) bool {
+	if matched, _ := path.Match(pattern, p); matched {
+		return true
+	}
+	matched, _ := path.Match(pattern, path.Base(p))
+	return matched
+}
+
+// isChanged checks if the local file differs from the remote file.
+func isChanged(file string, info os.FileInfo, remote *client.FileInfo, checksum bool, bclient BatchFileClient) (bool, error) {
+	if info.Size() != remote.Size() {
+		return true, nil
+	}
+	if !checksum {
+		return info.ModTime().After(remote.Modified()), nil
+	}
+	localHash, err := fileHash(file)
+	if err != nil {
+		return false, err
+	}
+	content, err := bclient.GetFileContent(remote.Path)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get the remote file "+remote.Path)
+	}
+	remoteHash := sha256.Sum256(content)
+	return !bytes.Equal(localHash, remoteHash[:]), nil
+}
+
+func fileHash(file string) ([]byte, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
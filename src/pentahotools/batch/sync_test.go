@@ -0,0 +1,111 @@
+package batch
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/uphy/pentahotools/client"
+	"gopkg.in/cheggaaa/pb.v1"
+)
+
+func TestPlanSync(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, file := range []string{"a.ktr", "b.txt", "sub/c.kjb"} {
+		file = filepath.Join(dir, file)
+		os.MkdirAll(filepath.Dir(file), 0755)
+		if err := ioutil.WriteFile(file, []byte("content"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Date(2017, 1, 1, 0, 0, 0, 0, time.Local)
+	os.Chtimes(filepath.Join(dir, "a.ktr"), old, old)
+
+	modified := fmt.Sprint(old.Add(time.Hour).UnixNano() / int64(time.Millisecond))
+	remoteFiles := map[string]client.FileInfo{
+		"/public/p":         {Path: "/public/p", Folder: "true"},
+		"/public/p/a.ktr":   {Path: "/public/p/a.ktr", FileSize: "7", LastModifiedDate: modified},
+		"/public/p/old.ktr": {Path: "/public/p/old.ktr", FileSize: "3"},
+		"/public/p/old.txt": {Path: "/public/p/old.txt", FileSize: "3"},
+	}
+	changes, err := planSync(dir, "/public/p", remoteFiles, &SyncOptions{
+		Delete:   true,
+		Includes: []string{"*.ktr", "*.kjb"},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual []string
+	for _, c := range changes {
+		actual = append(actual, c.Action+" "+c.RemotePath)
+	}
+	expected := []string{"mkdir /public/p/sub", "upload /public/p/sub/c.kjb", "delete /public/p/old.ktr"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v but %v", expected, actual)
+	}
+}
+
+func TestSyncOptionsMatches(t *testing.T) {
+	options := &SyncOptions{Includes: []string{"*.ktr"}, Excludes: []string{"tmp", "work/*.ktr"}}
+	for rel, expected := range map[string]bool{
+		"a.ktr":      true,
+		"a.kjb":      false,
+		"dir":        true,
+		"tmp":        false,
+		"work/a.ktr": false,
+		"dir/a.ktr":  true,
+	} {
+		if actual := options.matches(rel, filepath.Ext(rel) == ""); actual != expected {
+			t.Errorf("%s: expected %v but %v", rel, expected, actual)
+		}
+	}
+}
+
+// treeErrorClient is BatchFileClient whose Tree fails with the error.
+type treeErrorClient struct {
+	BatchFileClient
+	err error
+}
+
+func (c *treeErrorClient) Tree(path string, depth int, showHidden bool) (*client.FileEntry, error) {
+	return nil, c.err
+}
+
+func TestSyncFilesTreeError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "a.ktr"), []byte("content"), 0644)
+	logger := client.NewCompositeLogger()
+	options := &SyncOptions{DryRun: true}
+
+	// the missing directory is created.
+	changes, err := SyncFiles(dir, "/public/p", options, pb.New(0), &treeErrorClient{err: client.ErrNotFound}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual []string
+	for _, c := range changes {
+		actual = append(actual, c.Action+" "+c.RemotePath)
+	}
+	if expected := []string{"mkdir /public/p", "upload /public/p/a.ktr"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v but %v", expected, actual)
+	}
+
+	// the other errors are not treated as the missing directory.
+	changes, err = SyncFiles(dir, "/public/p", options, pb.New(0), &treeErrorClient{err: errors.New("Unknown error. statusCode=401")}, logger)
+	if err == nil || len(changes) != 0 {
+		t.Errorf("expected error, got %v, %v", changes, err)
+	}
+}
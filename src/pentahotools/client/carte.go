@@ -56,35 +56,37 @@ func (c *Client) RemoveJobOrTransformation(id, name string) error {
 }
 
 // Run runs a job.
-func (c *Client) Run(file string, level LogLevel) (string, error) {
+func (c *Client) Run(file string, level LogLevel, params map[string]string) (string, error) {
+	file, err := c.ResolveKettleFile(file)
+	if err != nil {
+		return "", err
+	}
 	if strings.HasSuffix(file, ".kjb") {
-		return c.JobClient.Run(file, level)
-	} else if strings.HasSuffix(file, ".ktr") {
-		return c.TransformationClient.Run(file, level)
-	} else {
-		// complements the kjb/ktr extension.
-		if !strings.HasPrefix(file, "/") {
-			file = "/" + file
-		}
-		dir, filename := filepath.Split(file)
-		dirEntry, err := c.Tree(dir, 1, false)
-		if err != nil {
-			return "", err
-		}
-		for _, f := range dirEntry.Children {
-			if strings.HasPrefix(f.File.Name, filename) {
-				switch f.File.Name {
-				case filename + ".kjb":
-					return c.JobClient.Run(file, level)
-				case filename + ".ktr":
-					return c.TransformationClient.Run(file, level)
-				default:
-					continue
-				}
-			}
+		return c.JobClient.Run(file, level, params)
+	}
+	return c.TransformationClient.Run(file, level, params)
+}
+
+// ResolveKettleFile complements the kjb/ktr extension of the file in the repository.
+func (c *Client) ResolveKettleFile(file string) (string, error) {
+	if strings.HasSuffix(file, ".kjb") || strings.HasSuffix(file, ".ktr") {
+		return file, nil
+	}
+	if !strings.HasPrefix(file, "/") {
+		file = "/" + file
+	}
+	dir, filename := filepath.Split(file)
+	dirEntry, err := c.Tree(dir, 1, false)
+	if err != nil {
+		return "", err
+	}
+	for _, f := range dirEntry.Children {
+		switch f.File.Name {
+		case filename + ".kjb", filename + ".ktr":
+			return dir + f.File.Name, nil
 		}
-		return "", errors.New("unknown file:" + file)
 	}
+	return "", errors.New("unknown file:" + file)
 }
 
 // webResult represents the result of the job or transformation.
@@ -116,6 +118,6 @@ func (c *Client) GetCarteClient(id string, name string) (CarteClient, error) {
 // CarteClient represents the carte job or transformation client.
 type CarteClient interface {
 	GetStatus(id string, name string, from int) (Status, error)
-	Run(file string, level LogLevel) (string, error)
+	Run(file string, level LogLevel, params map[string]string) (string, error)
 	Remove(id, name string) error
 }
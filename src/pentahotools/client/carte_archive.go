@@ -0,0 +1,75 @@
+package client
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// ArchiveOptions represents the options for ArchiveLog func.
+type ArchiveOptions struct {
+	Dir      string
+	Compress bool
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[\\/:*?"<>| ]`)
+
+// ArchiveLog writes the full log and the status snapshot of the job or transformation to the archive directory.
+// The files are written as '<dir>/<date>/<name>-<id>.log' and '<dir>/<date>/<name>-<id>.json'.
+// It returns the path of the log file.
+func (c *Client) ArchiveLog(id string, name string, options *ArchiveOptions) (string, error) {
+	c.Logger.Debug("ArchiveLog", zap.String("id", id), zap.String("name", name), zap.String("dir", options.Dir), zap.Bool("compress", options.Compress))
+	status, err := c.GetStatus(id, name, 0)
+	if err != nil {
+		return "", errors.Wrap(err, "getting status failure")
+	}
+	base := status.GetBaseStatus()
+	date := base.ParseLogDate()
+	if date.IsZero() {
+		date = time.Now()
+	}
+	dir := filepath.Join(options.Dir, date.Format("2006-01-02"))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", errors.Wrap(err, "failed to create the archive directory")
+	}
+	prefix := filepath.Join(dir, unsafeFileNameChars.ReplaceAllString(status.GetName(), "_")+"-"+base.ID)
+
+	snapshot, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal the status")
+	}
+	if _, err := writeArchiveFile(prefix+".json", snapshot, options.Compress); err != nil {
+		return "", err
+	}
+	return writeArchiveFile(prefix+".log", []byte(base.LoggingString), options.Compress)
+}
+
+func writeArchiveFile(file string, data []byte, compress bool) (string, error) {
+	if compress {
+		file = file + ".gz"
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create the archive file")
+	}
+	defer f.Close()
+	if compress {
+		w := gzip.NewWriter(f)
+		if _, err := w.Write(data); err != nil {
+			return "", errors.Wrap(err, "failed to write the archive file")
+		}
+		err = w.Close()
+	} else {
+		_, err = f.Write(data)
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "failed to write the archive file")
+	}
+	return file, nil
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// archiveCarteClient is CarteClient which returns the fixed status.
+type archiveCarteClient struct {
+	CarteClient
+	status Status
+}
+
+func (c *archiveCarteClient) GetStatus(id string, name string, from int) (Status, error) {
+	return c.status, nil
+}
+
+func TestArchiveLog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(`<serverstatus><transstatuslist><transstatus><transname>sales/load: daily</transname><id>t1</id><logging_string>&lt;![CDATA[]]&gt;</logging_string></transstatus></transstatuslist></serverstatus>`))
+	}))
+	defer server.Close()
+	c := NewClient(server.URL, "admin", "password")
+	status := &TransformationStatus{
+		BaseStatus: BaseStatus{ID: "t1", LogDate: "2017/10/25 10:00:00.000", LoggingString: "2017/10/25 10:00:00 - load - started"},
+		Name:       "sales/load: daily",
+		StepStatusList: StepStatusList{List: []StepStatus{
+			{Name: "input", LinesRead: 10, Seconds: 2},
+		}},
+	}
+	c.TransformationClient = &archiveCarteClient{status: status}
+
+	dir, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, compress := range []bool{false, true} {
+		ext := ""
+		if compress {
+			ext = ".gz"
+		}
+		file, err := c.ArchiveLog("t1", "", &ArchiveOptions{Dir: dir, Compress: compress})
+		if err != nil {
+			t.Fatal(err)
+		}
+		prefix := filepath.Join(dir, "2017-10-25", "sales_load__daily-t1")
+		if file != prefix+".log"+ext {
+			t.Errorf("unexpected log file: %s", file)
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var data []byte
+		if compress {
+			r, err := gzip.NewReader(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err = ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+		} else {
+			data, _ = ioutil.ReadAll(f)
+		}
+		f.Close()
+		if string(data) != status.LoggingString {
+			t.Errorf("expected %s but %s", status.LoggingString, data)
+		}
+
+		snapshot, err := LoadStatusSnapshot(prefix + ".json" + ext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if snapshot.Name != status.Name || snapshot.ID != "t1" || len(snapshot.StepStatusList.List) != 1 || snapshot.StepStatusList.List[0] != status.StepStatusList.List[0] {
+			t.Errorf("unexpected snapshot: %v", snapshot)
+		}
+		if snapshot.LoggingString != "" {
+			t.Errorf("the log should not be in the snapshot: %s", snapshot.LoggingString)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// blockoutActionClass is the action class of the blockout jobs.
+const blockoutActionClass = "org.pentaho.platform.scheduler2.blockout.BlockoutAction"
+
+// ListBlockouts lists all of the blockout jobs.
+func (c *Client) ListBlockouts() ([]Job, error) {
+	c.Logger.Debug("ListBlockouts")
+	var jobs jobList
+	resp, err := c.client.R().
+		SetHeader("Accept", "application/json").
+		SetResult(&jobs).
+		Get("api/scheduler/blockout/blockoutjobs")
+	switch resp.StatusCode() {
+	case 200:
+		return jobs.list()
+	case 403:
+		return nil, errors.New("User does not have administrative permissions")
+	case 500:
+		return nil, errors.New("server error")
+	default:
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("Unknown error. statusCode=%d", resp.StatusCode())
+	}
+}
+
+// AddBlockout adds the blockout job.
+// The trigger and the duration of the request are required.
+func (c *Client) AddBlockout(req *JobScheduleRequest) (string, error) {
+	c.Logger.Debug("AddBlockout", zap.Int64("duration", req.Duration))
+	if req.Duration <= 0 {
+		return "", errors.New("specify the duration of the blockout")
+	}
+	if err := req.Validate(); err != nil {
+		return "", err
+	}
+	resp, err := c.client.R().
+		SetBody(req).
+		SetHeader("Content-Type", "application/json").
+		SetHeader("Accept", "text/plain").
+		Post("api/scheduler/blockout/add")
+	switch resp.StatusCode() {
+	case 200:
+		return string(resp.Body()), nil
+	case 401:
+		return "", errors.New("User is not authorized to create blockout")
+	case 500:
+		return "", errors.New("server error")
+	default:
+		if err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("Unknown error. statusCode=%d", resp.StatusCode())
+	}
+}
+
+// RemoveBlockout removes the blockout job.
+func (c *Client) RemoveBlockout(jobID string) error {
+	c.Logger.Debug("RemoveBlockout", zap.String("jobID", jobID))
+	return c.RemoveJob(jobID)
+}
+
+// IsBlockout checks if the job is a blockout job.
+func (j *Job) IsBlockout() bool {
+	actionClass := j.JobParams.Get("ActionAdapterQuartzJob-ActionClass")
+	return actionClass != nil && *actionClass == blockoutActionClass
+}
+
+// BlockoutDuration gets the duration of the blockout job.
+func (j *Job) BlockoutDuration() time.Duration {
+	if j.JobTrigger.Duration > 0 {
+		return time.Duration(j.JobTrigger.Duration) * time.Millisecond
+	}
+	if duration := j.JobParams.Get("duration"); duration != nil {
+		if ms, err := strconv.ParseInt(*duration, 10, 64); err == nil {
+			return time.Duration(ms) * time.Millisecond
+		}
+	}
+	return 0
+}
+
+// FireTimes gets the fire times of the trigger in the period [from, to).
+// The cron and complex triggers require the cron expression. (see JobScheduleRequest.Trigger)
+func (t *JobTrigger) FireTimes(from time.Time, to time.Time) ([]time.Time, error) {
+	if t.EndTime != "" {
+		end, err := parseTriggerTime(t.EndTime)
+		if err != nil {
+			return nil, err
+		}
+		if end.Before(to) {
+			to = end
+		}
+	}
+	if t.Type == "simpleJobTrigger" {
+		if t.StartTime == "" {
+			return nil, fmt.Errorf("unsupported trigger without the start time: %s", t.Type)
+		}
+		start, err := parseTriggerTime(t.StartTime)
+		if err != nil {
+			return nil, err
+		}
+		return simpleFireTimes(start, time.Duration(t.RepeatInterval)*time.Second, int(t.RepeatCount), from, to), nil
+	}
+	if t.StartTime != "" {
+		start, err := parseTriggerTime(t.StartTime)
+		if err != nil {
+			return nil, err
+		}
+		if start.After(from) {
+			from = start
+		}
+	}
+	if t.CronString == "" {
+		return nil, fmt.Errorf("unsupported trigger without the cron expression: %s", t.Type)
+	}
+	schedule, err := ParseCronExpression(t.CronString)
+	if err != nil {
+		return nil, err
+	}
+	return schedule.FireTimes(from, to), nil
+}
+
+// simpleFireTimes gets the fire times of the simple trigger in the period [from, to).
+// The negative count means forever.
+func simpleFireTimes(start time.Time, interval time.Duration, count int, from time.Time, to time.Time) []time.Time {
+	var times []time.Time
+	if interval <= 0 {
+		if !start.Before(from) && start.Before(to) {
+			times = append(times, start)
+		}
+		return times
+	}
+	k := 0
+	if start.Before(from) {
+		k = int((from.Sub(start) + interval - 1) / interval)
+	}
+	for ; count < 0 || k <= count; k++ {
+		t := start.Add(time.Duration(k) * interval)
+		if !t.Before(to) {
+			break
+		}
+		times = append(times, t)
+	}
+	return times
+}
+
+func parseTriggerTime(s string) (time.Time, error) {
+	for _, layout := range []string{ScheduleTimeFormat, time.RFC3339} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Local(), nil
+		}
+	}
+	return time.Time{}, errors.New("invalid trigger time: " + s)
+}
+
+// BlockoutWindow is a period when the schedules must not run.
+type BlockoutWindow struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Contains checks if the time is in the window.
+func (w *BlockoutWindow) Contains(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+// BlockoutWindows gets the windows of the blockout in the period [from, to).
+func BlockoutWindows(trigger *JobTrigger, duration time.Duration, from time.Time, to time.Time) ([]BlockoutWindow, error) {
+	// windows which started before 'from' may still be open.
+	fireTimes, err := trigger.FireTimes(from.Add(-duration), to)
+	if err != nil {
+		return nil, err
+	}
+	var windows []BlockoutWindow
+	for _, t := range fireTimes {
+		windows = append(windows, BlockoutWindow{t, t.Add(duration)})
+	}
+	return windows, nil
+}
+
+// BlockoutConflict is a fire time of the schedule in the blockout window.
+type BlockoutConflict struct {
+	Job      Job
+	FireTime time.Time
+	Window   BlockoutWindow
+}
+
+// FindBlockoutConflicts finds the fire times of the jobs in the blockout windows in the period [from, to).
+// The blockout jobs are ignored, and the jobs whose fire times can not be computed are skipped with the warning.
+func FindBlockoutConflicts(jobs []Job, windows []BlockoutWindow, from time.Time, to time.Time, logger Logger) []BlockoutConflict {
+	var conflicts []BlockoutConflict
+	for _, job := range jobs {
+		if job.IsBlockout() {
+			continue
+		}
+		fireTimes, err := job.JobTrigger.FireTimes(from, to)
+		if err != nil {
+			logger.Warn("Skipped the schedule because the fire times can not be computed.", zap.String("jobName", job.JobName), zap.String("jobID", job.JobID), zap.Error(err))
+			continue
+		}
+		for _, t := range fireTimes {
+			for _, w := range windows {
+				if w.Contains(t) {
+					conflicts = append(conflicts, BlockoutConflict{job, t, w})
+					break
+				}
+			}
+		}
+	}
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		return conflicts[i].FireTime.Before(conflicts[j].FireTime)
+	})
+	return conflicts
+}
+
+// Trigger gets the trigger of the request in the form of the job info.
+func (r *JobScheduleRequest) Trigger() *JobTrigger {
+	switch {
+	case r.SimpleJobTrigger != nil:
+		return &JobTrigger{
+			Type:           "simpleJobTrigger",
+			RepeatInterval: jsonInt(r.SimpleJobTrigger.RepeatInterval),
+			RepeatCount:    jsonInt(r.SimpleJobTrigger.RepeatCount),
+			StartTime:      r.SimpleJobTrigger.StartTime,
+			EndTime:        r.SimpleJobTrigger.EndTime,
+		}
+	case r.CronJobTrigger != nil:
+		return &JobTrigger{
+			Type:       "cronJobTrigger",
+			CronString: r.CronJobTrigger.CronString,
+			StartTime:  r.CronJobTrigger.StartTime,
+			EndTime:    r.CronJobTrigger.EndTime,
+		}
+	case r.ComplexJobTrigger != nil:
+		return &JobTrigger{
+			Type:       "complexJobTrigger",
+			CronString: r.ComplexJobTrigger.CronString(),
+			StartTime:  r.ComplexJobTrigger.StartTime,
+			EndTime:    r.ComplexJobTrigger.EndTime,
+		}
+	}
+	return &JobTrigger{}
+}
+
+// CronString converts the trigger to the equivalent Quartz cron expression.
+func (t *ComplexJobTrigger) CronString() string {
+	daysOfMonth, daysOfWeek := "?", "?"
+	if len(t.DaysOfWeek) > 0 {
+		var days []string
+		for _, d := range t.DaysOfWeek {
+			if len(t.WeeksOfMonth) == 0 {
+				days = append(days, strconv.Itoa(d+1))
+				continue
+			}
+			for _, w := range t.WeeksOfMonth {
+				if w == 4 {
+					days = append(days, fmt.Sprintf("%dL", d+1))
+				} else {
+					days = append(days, fmt.Sprintf("%d#%d", d+1, w+1))
+				}
+			}
+		}
+		daysOfWeek = strings.Join(days, ",")
+	} else {
+		daysOfMonth = joinInts(t.DaysOfMonth, 0, "*")
+	}
+	fields := []string{
+		"0",
+		strconv.Itoa(t.MinuteOfHour),
+		strconv.Itoa(t.HourOfDay),
+		daysOfMonth,
+		joinInts(t.MonthsOfYear, 1, "*"),
+		daysOfWeek,
+	}
+	if len(t.Years) > 0 {
+		fields = append(fields, joinInts(t.Years, 0, "*"))
+	}
+	return strings.Join(fields, " ")
+}
+
+// joinInts joins the values added the offset with comma.
+func joinInts(values []int, offset int, empty string) string {
+	if len(values) == 0 {
+		return empty
+	}
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = strconv.Itoa(v + offset)
+	}
+	return strings.Join(s, ",")
+}
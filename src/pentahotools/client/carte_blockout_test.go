@@ -0,0 +1,69 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestComplexJobTriggerCronString(t *testing.T) {
+	for expected, trigger := range map[string]ComplexJobTrigger{
+		"0 30 2 ? * 2,3,4,5,6":  {DaysOfWeek: []int{1, 2, 3, 4, 5}, HourOfDay: 2, MinuteOfHour: 30},
+		"0 0 3 ? * 2#1,2L":      {DaysOfWeek: []int{1}, WeeksOfMonth: []int{0, 4}, HourOfDay: 3},
+		"0 0 0 1,15 * ?":        {DaysOfMonth: []int{1, 15}},
+		"0 0 0 1 1,4,7,10 ?":    {DaysOfMonth: []int{1}, MonthsOfYear: []int{0, 3, 6, 9}},
+		"0 0 0 * * ? 2018,2019": {Years: []int{2018, 2019}},
+	} {
+		if actual := trigger.CronString(); actual != expected {
+			t.Errorf("expected %s but %s", expected, actual)
+		}
+	}
+}
+
+func TestFindBlockoutConflicts(t *testing.T) {
+	from := time.Date(2017, 10, 2, 0, 0, 0, 0, time.Local)
+	to := from.AddDate(0, 0, 1)
+	start := from.Add(-time.Hour).Format(ScheduleTimeFormat)
+	jobs := []Job{
+		{JobName: "hourly", JobTrigger: JobTrigger{Type: "simpleJobTrigger", RepeatInterval: 3600, RepeatCount: -1, StartTime: start}},
+		{JobName: "nightly", JobTrigger: JobTrigger{Type: "cronJobTrigger", CronString: "0 30 2 * * ?"}},
+		{JobName: "once", JobTrigger: JobTrigger{Type: "simpleJobTrigger", StartTime: from.Add(5 * time.Hour).Format(ScheduleTimeFormat)}},
+		{JobName: "ended", JobTrigger: JobTrigger{Type: "simpleJobTrigger", RepeatInterval: 3600, RepeatCount: -1, StartTime: start, EndTime: from.Add(150 * time.Minute).Format(ScheduleTimeFormat)}},
+		{JobName: "nostart", JobTrigger: JobTrigger{Type: "simpleJobTrigger", RepeatInterval: 3600, RepeatCount: -1}},
+		{JobName: "nocron", JobTrigger: JobTrigger{Type: "complexJobTrigger", StartTime: start}},
+		{JobName: "complex", JobTrigger: *(&JobScheduleRequest{ComplexJobTrigger: &ComplexJobTrigger{HourOfDay: 2, MinuteOfHour: 15, StartTime: start}}).Trigger()},
+	}
+	blockout := &JobTrigger{Type: "cronJobTrigger", CronString: "0 0 2 * * ?"}
+	windows, err := BlockoutWindows(blockout, 90*time.Minute, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := &warnLogger{}
+	conflicts := FindBlockoutConflicts(jobs, windows, from, to, logger)
+	var actual []string
+	for _, c := range conflicts {
+		actual = append(actual, c.FireTime.Format("15:04")+" "+c.Job.JobName)
+	}
+	expected := []string{"02:00 hourly", "02:00 ended", "02:15 complex", "02:30 nightly", "03:00 hourly"}
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %v but %v", expected, actual)
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Errorf("expected %v but %v", expected, actual)
+		}
+	}
+	if len(logger.warnings) != 2 {
+		t.Errorf("expected the warnings for the triggers without the start time or the cron expression but %v", logger.warnings)
+	}
+}
+
+type warnLogger struct {
+	Logger
+	warnings []string
+}
+
+func (l *warnLogger) Warn(msg string, fields ...zapcore.Field) {
+	l.warnings = append(l.warnings, msg)
+}
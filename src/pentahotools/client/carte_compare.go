@@ -0,0 +1,118 @@
+package client
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// StepComparison represents the difference of a step between two executions.
+// Base or Target is nil if the step doesn't exist in the execution.
+type StepComparison struct {
+	Name   string
+	Base   *StepStatus
+	Target *StepStatus
+}
+
+// SecondsChange gets the change rate(%) of the seconds from the base execution.
+// It is +Inf if the step took the time while it took no time in the base execution.
+func (c *StepComparison) SecondsChange() float64 {
+	if c.Base == nil || c.Target == nil || c.Target.Seconds == c.Base.Seconds {
+		return 0
+	}
+	if c.Base.Seconds == 0 {
+		return math.Inf(1)
+	}
+	return float64((c.Target.Seconds - c.Base.Seconds) / c.Base.Seconds * 100)
+}
+
+// IsSlower checks if the step got slower than the threshold(%).
+func (c *StepComparison) IsSlower(threshold float64) bool {
+	return c.SecondsChange() > threshold
+}
+
+// CompareSteps lines up the steps of the two executions by step name.
+// The copies of a step are merged into one.
+func CompareSteps(base *TransformationStatus, target *TransformationStatus) []StepComparison {
+	baseSteps := mergeStepCopies(base.StepStatusList.List)
+	targetSteps := mergeStepCopies(target.StepStatusList.List)
+	var comparisons []StepComparison
+	for _, s := range baseSteps {
+		comparisons = append(comparisons, StepComparison{Name: s.Name, Base: s})
+	}
+	for _, s := range targetSteps {
+		found := false
+		for i := range comparisons {
+			if comparisons[i].Name == s.Name {
+				comparisons[i].Target = s
+				found = true
+				break
+			}
+		}
+		if !found {
+			comparisons = append(comparisons, StepComparison{Name: s.Name, Target: s})
+		}
+	}
+	return comparisons
+}
+
+func mergeStepCopies(steps []StepStatus) []*StepStatus {
+	var merged []*StepStatus
+	byName := map[string]*StepStatus{}
+	for _, s := range steps {
+		m, ok := byName[s.Name]
+		if !ok {
+			copied := s
+			byName[s.Name] = &copied
+			merged = append(merged, &copied)
+			continue
+		}
+		m.LinesRead += s.LinesRead
+		m.LinesWritten += s.LinesWritten
+		m.LinesInput += s.LinesInput
+		m.LinesOutput += s.LinesOutput
+		m.LinesUpdated += s.LinesUpdated
+		m.LinesRejected += s.LinesRejected
+		m.Errors += s.Errors
+		if s.Seconds > m.Seconds {
+			m.Seconds = s.Seconds
+		}
+		m.Speed = strconv.FormatFloat(m.ParseSpeed()+s.ParseSpeed(), 'f', 0, 64)
+	}
+	return merged
+}
+
+// ParseSpeed parses the speed(rows/s) of the step.
+func (s *StepStatus) ParseSpeed() float64 {
+	speed, _ := strconv.ParseFloat(strings.Replace(strings.TrimSpace(s.Speed), ",", "", -1), 64)
+	return speed
+}
+
+// LoadStatusSnapshot loads the status snapshot of the transformation written by ArchiveLog.
+func LoadStatusSnapshot(file string) (*TransformationStatus, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open the snapshot")
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if strings.HasSuffix(file, ".gz") {
+		gzipReader, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to open the snapshot")
+		}
+		defer gzipReader.Close()
+		r = gzipReader
+	}
+	var status TransformationStatus
+	if err := json.NewDecoder(r).Decode(&status); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the snapshot")
+	}
+	return &status, nil
+}
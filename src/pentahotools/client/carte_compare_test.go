@@ -0,0 +1,104 @@
+package client
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMergeStepCopies(t *testing.T) {
+	merged := mergeStepCopies([]StepStatus{
+		{Name: "input", LinesRead: 100, LinesWritten: 100, Seconds: 2, Speed: "50"},
+		{Name: "lookup", Copy: 0, LinesRead: 40, LinesWritten: 30, LinesRejected: 10, Errors: 1, Seconds: 3, Speed: "1,000"},
+		{Name: "lookup", Copy: 1, LinesRead: 60, LinesWritten: 60, Seconds: 4, Speed: " 500 "},
+		{Name: "output", LinesOutput: 90, Seconds: 4, Speed: ""},
+	})
+	expected := []StepStatus{
+		{Name: "input", LinesRead: 100, LinesWritten: 100, Seconds: 2, Speed: "50"},
+		{Name: "lookup", LinesRead: 100, LinesWritten: 90, LinesRejected: 10, Errors: 1, Seconds: 4, Speed: "1500"},
+		{Name: "output", LinesOutput: 90, Seconds: 4, Speed: ""},
+	}
+	if len(merged) != len(expected) {
+		t.Fatalf("expected %d steps but %d steps", len(expected), len(merged))
+	}
+	for i, e := range expected {
+		if *merged[i] != e {
+			t.Errorf("expected %v but %v", e, *merged[i])
+		}
+	}
+}
+
+func TestCompareSteps(t *testing.T) {
+	base := &TransformationStatus{StepStatusList: StepStatusList{List: []StepStatus{
+		{Name: "input", Seconds: 10},
+		{Name: "lookup", Seconds: 4},
+		{Name: "lookup", Copy: 1, Seconds: 5},
+		{Name: "removed", Seconds: 1},
+	}}}
+	target := &TransformationStatus{StepStatusList: StepStatusList{List: []StepStatus{
+		{Name: "added", Seconds: 3},
+		{Name: "lookup", Seconds: 9},
+		{Name: "input", Seconds: 12},
+	}}}
+	expected := []struct {
+		name    string
+		base    float32
+		target  float32
+		change  float64
+		missing string
+	}{
+		{"input", 10, 12, 20, ""},
+		{"lookup", 5, 9, 80, ""},
+		{"removed", 1, 0, 0, "target"},
+		{"added", 0, 3, 0, "base"},
+	}
+	comparisons := CompareSteps(base, target)
+	if len(comparisons) != len(expected) {
+		t.Fatalf("expected %d steps but %d steps", len(expected), len(comparisons))
+	}
+	for i, e := range expected {
+		c := comparisons[i]
+		if c.Name != e.name || c.SecondsChange() != e.change {
+			t.Errorf("expected %v but %s %f", e, c.Name, c.SecondsChange())
+		}
+		switch e.missing {
+		case "base":
+			if c.Base != nil || c.Target == nil || c.Target.Seconds != e.target {
+				t.Errorf("%s should exist only in the target: %v", e.name, c)
+			}
+		case "target":
+			if c.Target != nil || c.Base == nil || c.Base.Seconds != e.base {
+				t.Errorf("%s should exist only in the base: %v", e.name, c)
+			}
+		default:
+			if c.Base == nil || c.Target == nil || c.Base.Seconds != e.base || c.Target.Seconds != e.target {
+				t.Errorf("expected %v but %v", e, c)
+			}
+		}
+	}
+}
+
+func TestSecondsChange(t *testing.T) {
+	for _, c := range []struct {
+		base     *StepStatus
+		target   *StepStatus
+		expected float64
+		slower   bool
+	}{
+		{&StepStatus{Seconds: 10}, &StepStatus{Seconds: 15}, 50, true},
+		{&StepStatus{Seconds: 10}, &StepStatus{Seconds: 5}, -50, false},
+		{&StepStatus{Seconds: 10}, &StepStatus{Seconds: 10}, 0, false},
+		{&StepStatus{Seconds: 0}, &StepStatus{Seconds: 30}, math.Inf(1), true},
+		{&StepStatus{Seconds: 0}, &StepStatus{Seconds: 0}, 0, false},
+		{&StepStatus{Seconds: 10}, &StepStatus{Seconds: 0}, -100, false},
+		{nil, &StepStatus{Seconds: 5}, 0, false},
+		{&StepStatus{Seconds: 5}, nil, 0, false},
+	} {
+		comparison := StepComparison{Name: "step", Base: c.base, Target: c.target}
+		if actual := comparison.SecondsChange(); actual != c.expected {
+			t.Errorf("expected %f but %f: %v -> %v", c.expected, actual, c.base, c.target)
+		}
+		if comparison.IsSlower(10) != c.slower {
+			t.Errorf("expected slower=%v: %v -> %v", c.slower, c.base, c.target)
+		}
+	}
+}
@@ -54,16 +54,19 @@ func (c *JobClient) GetStatus(id string, name string, from int) (Status, error)
 }
 
 // Run runs a job
-func (c *JobClient) Run(file string, level LogLevel) (string, error) {
-	c.logger.Debug("RunJob", zap.String("file", file))
+func (c *JobClient) Run(file string, level LogLevel, params map[string]string) (string, error) {
+	c.logger.Debug("RunJob", zap.String("file", file), zap.Any("params", params))
 	if strings.HasSuffix(file, ".kjb") {
 		file = file[0 : len(file)-4]
 	}
+	formData := map[string]string{}
+	for name, value := range params {
+		formData[name] = value
+	}
+	formData["job"] = file
+	formData["level"] = string(level)
 	resp, err := c.client.R().
-		SetFormData(map[string]string{
-			"job":   file,
-			"level": string(level),
-		}).
+		SetFormData(formData).
 		SetHeader("Accept", "*/*").
 		Post("kettle/runJob/")
 	switch resp.StatusCode() {
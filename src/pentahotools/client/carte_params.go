@@ -0,0 +1,73 @@
+package client
+
+import (
+	"encoding/xml"
+	"sort"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// Parameter represents a named parameter declared in a job or transformation.
+type Parameter struct {
+	Name         string `xml:"name"`
+	DefaultValue string `xml:"default_value"`
+	Description  string `xml:"description"`
+}
+
+// IsRequired checks if the parameter has no default value.
+func (p *Parameter) IsRequired() bool {
+	return p.DefaultValue == ""
+}
+
+// kettleFile is the part of the .kjb/.ktr XML which declares the parameters.
+type kettleFile struct {
+	JobParameters            []Parameter `xml:"parameters>parameter"`
+	TransformationParameters []Parameter `xml:"info>parameters>parameter"`
+}
+
+// GetParameters gets the parameters declared in the job or transformation.
+func (c *Client) GetParameters(file string) ([]Parameter, error) {
+	c.Logger.Debug("GetParameters", zap.String("file", file))
+	file, err := c.ResolveKettleFile(file)
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.GetFileContent(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the content of "+file)
+	}
+	params, err := parseParameters(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse "+file)
+	}
+	return params, nil
+}
+
+// parseParameters parses the parameters declared in the .kjb/.ktr XML.
+func parseParameters(data []byte) ([]Parameter, error) {
+	var f kettleFile
+	if err := xml.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	return append(f.JobParameters, f.TransformationParameters...), nil
+}
+
+// CheckParameters compares the specified parameter values with the declared parameters.
+// It returns the names which are not declared, and the names of required parameters which are not specified.
+func CheckParameters(declared []Parameter, values map[string]string) (unknown []string, missing []string) {
+	names := map[string]bool{}
+	for _, p := range declared {
+		names[p.Name] = true
+		if _, ok := values[p.Name]; !ok && p.IsRequired() {
+			missing = append(missing, p.Name)
+		}
+	}
+	for name := range values {
+		if !names[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown, missing
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testJob = `<?xml version="1.0" encoding="UTF-8"?>
+<job>
+  <name>load</name>
+  <parameters>
+    <parameter>
+      <name>DATE</name>
+      <default_value/>
+      <description>the target date</description>
+    </parameter>
+    <parameter>
+      <name>REGION</name>
+      <default_value>east</default_value>
+      <description/>
+    </parameter>
+  </parameters>
+  <entries>
+    <entry>
+      <name>transform</name>
+      <parameters>
+        <pass_all_parameters>Y</pass_all_parameters>
+        <parameter>
+          <name>ENTRY_PARAM</name>
+          <stream_name/>
+          <value>${DATE}</value>
+        </parameter>
+      </parameters>
+    </entry>
+  </entries>
+</job>`
+
+const testTransformation = `<?xml version="1.0" encoding="UTF-8"?>
+<transformation>
+  <info>
+    <name>transform</name>
+    <parameters>
+      <parameter>
+        <name>LIMIT</name>
+        <default_value>100</default_value>
+        <description>the max rows</description>
+      </parameter>
+    </parameters>
+  </info>
+  <step>
+    <name>input</name>
+  </step>
+</transformation>`
+
+func TestParseParameters(t *testing.T) {
+	for data, expected := range map[string][]Parameter{
+		testJob: {
+			{Name: "DATE", Description: "the target date"},
+			{Name: "REGION", DefaultValue: "east"},
+		},
+		testTransformation: {
+			{Name: "LIMIT", DefaultValue: "100", Description: "the max rows"},
+		},
+		`<transformation><info><name>empty</name></info></transformation>`: nil,
+	} {
+		params, err := parseParameters([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(params, expected) {
+			t.Errorf("expected %v but %v", expected, params)
+		}
+	}
+	if _, err := parseParameters([]byte("<job>")); err == nil {
+		t.Error("expected error for the broken XML")
+	}
+}
+
+func TestCheckParameters(t *testing.T) {
+	declared := []Parameter{
+		{Name: "DATE"},
+		{Name: "REGION", DefaultValue: "east"},
+		{Name: "USER"},
+	}
+	for _, c := range []struct {
+		values  map[string]string
+		unknown []string
+		missing []string
+	}{
+		{map[string]string{"DATE": "2017-10-25", "USER": "admin"}, nil, nil},
+		{map[string]string{"DATE": "", "USER": "admin", "REGION": "west"}, nil, nil},
+		{map[string]string{"USER": "admin"}, nil, []string{"DATE"}},
+		{map[string]string{}, nil, []string{"DATE", "USER"}},
+		{map[string]string{"DATE": "2017-10-25", "USER": "admin", "ZONE": "a", "LIMIT": "1"}, []string{"LIMIT", "ZONE"}, nil},
+	} {
+		unknown, missing := CheckParameters(declared, c.values)
+		if !reflect.DeepEqual(unknown, c.unknown) || !reflect.DeepEqual(missing, c.missing) {
+			t.Errorf("expected %v, %v but %v, %v for %v", c.unknown, c.missing, unknown, missing, c.values)
+		}
+	}
+}
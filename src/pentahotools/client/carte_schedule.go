@@ -1,8 +1,14 @@
 package client
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -34,6 +40,9 @@ func (c *Client) GetJobInfo(jobID string) (*Job, error) {
 // ScheduleJob schedules job.
 func (c *Client) ScheduleJob(req *JobScheduleRequest) (string, error) {
 	c.Logger.Debug("ScheduleJob")
+	if err := req.Validate(); err != nil {
+		return "", err
+	}
 	resp, err := c.client.R().
 		SetBody(req).
 		SetHeader("Content-Type", "application/json").
@@ -54,13 +63,284 @@ func (c *Client) ScheduleJob(req *JobScheduleRequest) (string, error) {
 	}
 }
 
+// ListJobs lists all of the scheduled jobs.
+func (c *Client) ListJobs() ([]Job, error) {
+	c.Logger.Debug("ListJobs")
+	var jobs jobList
+	resp, err := c.client.R().
+		SetQueryParam("asCronString", "false").
+		SetHeader("Accept", "application/json").
+		SetResult(&jobs).
+		Get("api/scheduler/getJobs")
+	switch resp.StatusCode() {
+	case 200:
+		return jobs.list()
+	case 403:
+		return nil, errors.New("User does not have administrative permissions")
+	case 500:
+		return nil, errors.New("server error")
+	default:
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("Unknown error. statusCode=%d", resp.StatusCode())
+	}
+}
+
+// RemoveJob removes the scheduled job.
+func (c *Client) RemoveJob(jobID string) error {
+	c.Logger.Debug("RemoveJob", zap.String("jobID", jobID))
+	resp, err := c.client.R().
+		SetBody(jobRequest{jobID}).
+		SetHeader("Content-Type", "application/json").
+		SetHeader("Accept", "text/plain").
+		Delete("api/scheduler/removeJob")
+	switch resp.StatusCode() {
+	case 200:
+		return nil
+	case 403:
+		return errors.New("User does not have administrative permissions")
+	case 500:
+		return errors.New("server error")
+	default:
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("Unknown error. statusCode=%d", resp.StatusCode())
+	}
+}
+
+// PauseJob pauses the scheduled job.
+// It returns the state of the job.
+func (c *Client) PauseJob(jobID string) (string, error) {
+	c.Logger.Debug("PauseJob", zap.String("jobID", jobID))
+	return c.postScheduler("api/scheduler/pauseJob", &jobRequest{jobID})
+}
+
+// ResumeJob resumes the paused job.
+// It returns the state of the job.
+func (c *Client) ResumeJob(jobID string) (string, error) {
+	c.Logger.Debug("ResumeJob", zap.String("jobID", jobID))
+	return c.postScheduler("api/scheduler/resumeJob", &jobRequest{jobID})
+}
+
+// TriggerJobNow runs the scheduled job immediately.
+// It returns the state of the job.
+func (c *Client) TriggerJobNow(jobID string) (string, error) {
+	c.Logger.Debug("TriggerJobNow", zap.String("jobID", jobID))
+	return c.postScheduler("api/scheduler/triggerNow", &jobRequest{jobID})
+}
+
+// PauseScheduler pauses the whole of the scheduler.
+// It returns the state of the scheduler.
+func (c *Client) PauseScheduler() (string, error) {
+	c.Logger.Debug("PauseScheduler")
+	return c.postScheduler("api/scheduler/pause", nil)
+}
+
+// StartScheduler starts the paused scheduler.
+// It returns the state of the scheduler.
+func (c *Client) StartScheduler() (string, error) {
+	c.Logger.Debug("StartScheduler")
+	return c.postScheduler("api/scheduler/start", nil)
+}
+
+// GetSchedulerState gets the state of the scheduler.
+func (c *Client) GetSchedulerState() (string, error) {
+	c.Logger.Debug("GetSchedulerState")
+	resp, err := c.client.R().
+		SetHeader("Accept", "text/plain").
+		Get("api/scheduler/state")
+	switch resp.StatusCode() {
+	case 200:
+		return string(resp.Body()), nil
+	case 500:
+		return "", errors.New("server error")
+	default:
+		if err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("Unknown error. statusCode=%d", resp.StatusCode())
+	}
+}
+
+func (c *Client) postScheduler(apiPath string, body *jobRequest) (string, error) {
+	req := c.client.R().
+		SetHeader("Accept", "text/plain")
+	if body != nil {
+		req.SetBody(body).
+			SetHeader("Content-Type", "application/json")
+	}
+	resp, err := req.Post(apiPath)
+	switch resp.StatusCode() {
+	case 200:
+		return string(resp.Body()), nil
+	case 403:
+		return "", errors.New("User does not have administrative permissions")
+	case 500:
+		return "", errors.New("server error")
+	default:
+		if err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("Unknown error. statusCode=%d", resp.StatusCode())
+	}
+}
+
+// jobRequest is the request body to specify a scheduled job.
+type jobRequest struct {
+	JobID string `json:"jobId"`
+}
+
+// jobList is the response of getJobs API.
+// The job is serialized as an object instead of an array if there is only one job.
+type jobList struct {
+	Job json.RawMessage `json:"job"`
+}
+
+func (l *jobList) list() ([]Job, error) {
+	if len(l.Job) == 0 {
+		return nil, nil
+	}
+	if l.Job[0] == '{' {
+		var job Job
+		if err := json.Unmarshal(l.Job, &job); err != nil {
+			return nil, err
+		}
+		return []Job{job}, nil
+	}
+	var jobs []Job
+	if err := json.Unmarshal(l.Job, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
 // Job represents a schedule job.
 type Job struct {
-	JobID     string
-	State     string
-	JobParams JobParams
-	NextRun   string
-	UserName  string
+	JobID      string
+	JobName    string
+	State      string
+	JobParams  JobParams
+	JobTrigger JobTrigger
+	NextRun    string
+	LastRun    string
+	UserName   string
+}
+
+// JobTrigger represents the trigger of a scheduled job.
+// Type is one of 'simpleJobTrigger', 'cronJobTrigger' or 'complexJobTrigger'.
+// CronString is also available for the complex trigger.
+type JobTrigger struct {
+	Type           string  `json:"@type"`
+	UIPassParam    string  `json:"uiPassParam"`
+	CronString     string  `json:"cronString"`
+	RepeatInterval jsonInt `json:"repeatInterval"`
+	RepeatCount    jsonInt `json:"repeatCount"`
+	StartTime      string  `json:"startTime"`
+	EndTime        string  `json:"endTime"`
+	Duration       jsonInt `json:"duration"`
+}
+
+// String describes the trigger.
+func (t *JobTrigger) String() string {
+	switch {
+	case t.Type == "simpleJobTrigger":
+		return fmt.Sprintf("every %d seconds (count=%d)", t.RepeatInterval, t.RepeatCount)
+	case t.CronString != "":
+		return "cron " + t.CronString
+	}
+	return t.Type
+}
+
+// jsonInt is an int which can be unmarshaled from both of JSON number and string.
+type jsonInt int
+
+func (i *jsonInt) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		*i = 0
+		return nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*i = jsonInt(v)
+	return nil
+}
+
+// streamProviderPattern matches the stream provider parameter. (e.g., "input file = /public/report.prpt:outputFile = /home/admin/report.*")
+var streamProviderPattern = regexp.MustCompile(`^input file = (.*):outputFile = (.*)$`)
+
+// InputFile gets the input file of the job.
+func (j *Job) InputFile() string {
+	if inputFile := j.JobParams.Get("ActionAdapterQuartzJob-StreamProvider-InputFile"); inputFile != nil {
+		return *inputFile
+	}
+	if streamProvider := j.JobParams.Get("ActionAdapterQuartzJob-StreamProvider"); streamProvider != nil {
+		if m := streamProviderPattern.FindStringSubmatch(*streamProvider); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// OutputFile gets the output file pattern of the job.
+func (j *Job) OutputFile() string {
+	if streamProvider := j.JobParams.Get("ActionAdapterQuartzJob-StreamProvider"); streamProvider != nil {
+		if m := streamProviderPattern.FindStringSubmatch(*streamProvider); m != nil {
+			return m[2]
+		}
+	}
+	return ""
+}
+
+// OutputFolder gets the folder where the job generates the content.
+func (j *Job) OutputFolder() string {
+	return path.Dir(j.OutputFile())
+}
+
+// IsOutput checks if the file is generated by the job.
+// The generated content is named after the output file pattern with the date if appendDateFormat is set, the counter for the duplicates, and the extension.
+// (e.g., "/home/admin/report.*" generates "/home/admin/report.pdf" and "/home/admin/report(1).pdf", but not "/home/admin/report_summary.pdf")
+func (j *Job) IsOutput(file *FileInfo) bool {
+	outputFile := j.OutputFile()
+	if outputFile == "" || file.IsFolder() || path.Dir(file.Path) != path.Dir(outputFile) {
+		return false
+	}
+	pattern := regexp.QuoteMeta(strings.TrimSuffix(path.Base(outputFile), ".*"))
+	if dateFormat := j.JobParams.Get("appendDateFormat"); dateFormat != nil && *dateFormat != "" {
+		pattern += dateFormatPattern(*dateFormat)
+	}
+	matched, _ := regexp.MatchString("^"+pattern+`(\(\d+\))?\.[^.]+$`, file.Name)
+	return matched
+}
+
+// dateFormatPattern converts the Java date format to the regular expression matching the formatted date.
+// The letters are matched with the digits or the letters, and the quoted text is matched literally.
+func dateFormatPattern(format string) string {
+	var pattern string
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		switch {
+		case c == '\'':
+			end := strings.IndexByte(format[i+1:], '\'')
+			if end < 0 {
+				end = len(format) - i - 1
+			}
+			pattern += regexp.QuoteMeta(format[i+1 : i+1+end])
+			i += end + 1
+		case ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z'):
+			for i+1 < len(format) && format[i+1] == c {
+				i++
+			}
+			pattern += `[0-9A-Za-z]+`
+		default:
+			pattern += regexp.QuoteMeta(string(c))
+		}
+	}
+	return pattern
 }
 
 // JobParam is a job parameters
@@ -69,6 +349,42 @@ type JobParam struct {
 	Value string
 }
 
+// internalJobParams are the prefixes of the job parameters managed by the scheduler.
+var internalJobParams = []string{"ActionAdapterQuartzJob-", "lineage-id", "uiPassParam", "user_locale", "::session"}
+
+// IsInternal checks if the parameter is managed by the scheduler.
+func (p *JobParam) IsInternal() bool {
+	for _, prefix := range internalJobParams {
+		if strings.HasPrefix(p.Name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// javaDateFormat is the format of java.util.Date#toString, in which the scheduler API returns the date parameters.
+const javaDateFormat = "Mon Jan 02 15:04:05 MST 2006"
+
+// javaDoublePattern matches the numbers formatted by java.lang.Double#toString, which always have the fraction part. (e.g., "100.0", "1.5E-3")
+// The integers are not matched because they can not be distinguished from the numeric strings such as the codes.
+var javaDoublePattern = regexp.MustCompile(`^-?(0|[1-9]\d*)\.\d+(E-?\d+)?$`)
+
+// TypedValue gets the type of the parameter and the value in the format of JobParameter.
+// The type is one of JobParameterTypes, detected from the value because the scheduler API returns the values as the strings.
+// It is 'string' unless the value is in the format which Java writes for the other types.
+func (p *JobParam) TypedValue() (string, string) {
+	switch {
+	case p.Value == "true" || p.Value == "false":
+		return "boolean", p.Value
+	case javaDoublePattern.MatchString(p.Value):
+		return "number", p.Value
+	}
+	if t, err := time.Parse(javaDateFormat, p.Value); err == nil {
+		return "date", t.Format(ScheduleTimeFormat)
+	}
+	return "string", p.Value
+}
+
 // JobParams is a list of JobParams
 type JobParams struct {
 	JobParams []JobParam
@@ -84,14 +400,52 @@ func (p *JobParams) Get(name string) *string {
 	return nil
 }
 
+// ScheduleTimeFormat is the time format of the scheduler API.
+const ScheduleTimeFormat = "2006-01-02T15:04:05.000-07:00"
+
 // JobScheduleRequest is the specification for a schedule.
+// Specify one of SimpleJobTrigger, CronJobTrigger or ComplexJobTrigger.
 type JobScheduleRequest struct {
-	JobName          string            `json:"jobName,omitempty"`
-	SimpleJobTrigger *SimpleJobTrigger `json:"simpleJobTrigger,omitempty"`
-	InputFile        string            `json:"inputFile"`
-	OutputFile       string            `json:"outputFile,omitempty"`
-	RunInBackground  bool              `json:"runInBackground"`
-	JobParameters    *JobParameters    `json:"jobParameters,omitempty"`
+	JobName           string             `json:"jobName,omitempty"`
+	SimpleJobTrigger  *SimpleJobTrigger  `json:"simpleJobTrigger,omitempty"`
+	CronJobTrigger    *CronJobTrigger    `json:"cronJobTrigger,omitempty"`
+	ComplexJobTrigger *ComplexJobTrigger `json:"complexJobTrigger,omitempty"`
+	InputFile         string             `json:"inputFile"`
+	// OutputFile is the folder of the generated content, which is named after the job name.
+	OutputFile string `json:"outputFile,omitempty"`
+	// AppendDateFormat is the date format appended to the name of the generated content. (e.g., "yyyy-MM-dd")
+	AppendDateFormat string         `json:"appendDateFormat,omitempty"`
+	RunInBackground  bool           `json:"runInBackground"`
+	JobParameters    []JobParameter `json:"jobParameters,omitempty"`
+	// Duration is the duration of the blockout in milliseconds.
+	Duration int64  `json:"duration,omitempty"`
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// Validate validates the request before sending it.
+func (r *JobScheduleRequest) Validate() error {
+	triggers := 0
+	if r.SimpleJobTrigger != nil {
+		triggers++
+	}
+	if r.CronJobTrigger != nil {
+		triggers++
+		if err := ValidateCronExpression(r.CronJobTrigger.CronString); err != nil {
+			return err
+		}
+	}
+	if r.ComplexJobTrigger != nil {
+		triggers++
+	}
+	if triggers != 1 {
+		return errors.New("specify exactly one trigger")
+	}
+	for i := range r.JobParameters {
+		if err := r.JobParameters[i].Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // SimpleJobTrigger represents a trigger of the schedule.
@@ -103,9 +457,85 @@ type SimpleJobTrigger struct {
 	EndTime        string `json:"endTime,omitempty"`
 }
 
-// JobParameters is the parameter of the job.
-type JobParameters struct {
-	Name        string `json:"name,omitempty"`
-	Type        string `json:"type,omitempty"`
-	StringValue string `json:"stringValue,omitempty"`
+// CronJobTrigger represents a trigger by the Quartz cron expression.
+type CronJobTrigger struct {
+	UIPassParam string `json:"uiPassParam,omitempty"`
+	CronString  string `json:"cronString"`
+	StartTime   string `json:"startTime,omitempty"`
+	EndTime     string `json:"endTime,omitempty"`
+}
+
+// ComplexJobTrigger represents a trigger by the days of week/month, weeks of month and months.
+// DaysOfWeek is numbered from 0(Sunday), WeeksOfMonth is numbered from 0(first) to 4(last) and MonthsOfYear is numbered from 0(January).
+type ComplexJobTrigger struct {
+	UIPassParam  string `json:"uiPassParam,omitempty"`
+	DaysOfWeek   []int  `json:"daysOfWeek,omitempty"`
+	WeeksOfMonth []int  `json:"weeksOfMonth,omitempty"`
+	DaysOfMonth  []int  `json:"daysOfMonth,omitempty"`
+	MonthsOfYear []int  `json:"monthsOfYear,omitempty"`
+	Years        []int  `json:"years,omitempty"`
+	HourOfDay    int    `json:"hourOfDay"`
+	MinuteOfHour int    `json:"minuteOfHour"`
+	StartTime    string `json:"startTime,omitempty"`
+	EndTime      string `json:"endTime,omitempty"`
+}
+
+// ReportOutputTargets are the output targets of the report(.prpt) by the format.
+var ReportOutputTargets = map[string]string{
+	"pdf":  "pageable/pdf",
+	"html": "table/html;page-mode=stream",
+	"xls":  "table/excel;page-mode=flow",
+	"xlsx": "table/xlsx;page-mode=flow",
+	"csv":  "table/csv;page-mode=stream",
+	"rtf":  "table/rtf;page-mode=flow",
+	"txt":  "pageable/text",
+}
+
+// IsReportFile checks if the file is a report which generates content. (.prpt or .xanalyzer)
+func IsReportFile(file string) bool {
+	switch path.Ext(file) {
+	case ".prpt", ".xanalyzer":
+		return true
+	}
+	return false
+}
+
+// JobParameterTypes are the available types of JobParameter.
+var JobParameterTypes = []string{"string", "number", "date", "boolean"}
+
+// JobParameter is a typed parameter of the job.
+// Multiple values make a list parameter.
+type JobParameter struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	StringValue []string `json:"stringValue"`
+}
+
+// Validate validates the type and the values of the parameter.
+// The date values are normalized to ScheduleTimeFormat, which the scheduler API accepts.
+func (p *JobParameter) Validate() error {
+	for i, value := range p.StringValue {
+		var err error
+		switch p.Type {
+		case "string":
+		case "number":
+			_, err = strconv.ParseFloat(value, 64)
+		case "date":
+			var t time.Time
+			if t, err = time.ParseInLocation("2006-01-02", value, time.Local); err != nil {
+				t, err = time.Parse(ScheduleTimeFormat, value)
+			}
+			if err == nil {
+				p.StringValue[i] = t.Format(ScheduleTimeFormat)
+			}
+		case "boolean":
+			_, err = strconv.ParseBool(value)
+		default:
+			return fmt.Errorf("unsupported parameter type: %s (%s)", p.Type, strings.Join(JobParameterTypes, "/"))
+		}
+		if err != nil {
+			return fmt.Errorf("invalid %s value of parameter '%s': %s", p.Type, p.Name, value)
+		}
+	}
+	return nil
 }
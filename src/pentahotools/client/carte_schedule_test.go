@@ -0,0 +1,83 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestJobIsOutput(t *testing.T) {
+	newJob := func(outputFile string, params ...JobParam) *Job {
+		job := &Job{}
+		job.JobParams.JobParams = append(params, JobParam{"ActionAdapterQuartzJob-StreamProvider", "input file = /public/report.prpt:outputFile = " + outputFile})
+		return job
+	}
+	job := newJob("/home/admin/report.*")
+	dated := newJob("/home/admin/report.*", JobParam{"appendDateFormat", "yyyy-MM-dd'T'HH"})
+	for _, c := range []struct {
+		job      *Job
+		file     string
+		expected bool
+	}{
+		{job, "/home/admin/report.pdf", true},
+		{job, "/home/admin/report(1).pdf", true},
+		{job, "/home/admin/report_summary.pdf", false},
+		{job, "/home/admin/report2.pdf", false},
+		{job, "/home/admin/report", false},
+		{job, "/home/admin/report.pdf.bak", false},
+		{job, "/home/suzy/report.pdf", false},
+		{dated, "/home/admin/report2017-10-01T09.pdf", true},
+		{dated, "/home/admin/report2017-10-01T09(2).pdf", true},
+		{dated, "/home/admin/report_summary2017-10-01T09.pdf", false},
+		{dated, "/home/admin/report2017-10-01.pdf", false},
+	} {
+		file := &FileInfo{Path: c.file, Name: c.file[len("/home/admin/"):]}
+		if actual := c.job.IsOutput(file); actual != c.expected {
+			t.Errorf("%s: expected %v, got %v", c.file, c.expected, actual)
+		}
+	}
+	if newJob("").IsOutput(&FileInfo{Path: "/home/admin/report.pdf", Name: "report.pdf"}) {
+		t.Error("the job without the output should not have the outputs")
+	}
+}
+
+func TestJobParameterValidate(t *testing.T) {
+	date := time.Date(2017, 10, 25, 0, 0, 0, 0, time.Local).Format(ScheduleTimeFormat)
+	for _, c := range []struct {
+		param    JobParameter
+		expected []string
+	}{
+		{JobParameter{Name: "d", Type: "date", StringValue: []string{"2017-10-25"}}, []string{date}},
+		{JobParameter{Name: "d", Type: "date", StringValue: []string{"2017-10-25T10:00:00.000+09:00"}}, []string{"2017-10-25T10:00:00.000+09:00"}},
+		{JobParameter{Name: "n", Type: "number", StringValue: []string{"01234", "1.5"}}, []string{"01234", "1.5"}},
+		{JobParameter{Name: "b", Type: "boolean", StringValue: []string{"true"}}, []string{"true"}},
+		{JobParameter{Name: "s", Type: "string", StringValue: []string{"2017-10-25"}}, []string{"2017-10-25"}},
+		{JobParameter{Name: "d", Type: "date", StringValue: []string{"2017/10/25"}}, nil},
+		{JobParameter{Name: "n", Type: "number", StringValue: []string{"abc"}}, nil},
+		{JobParameter{Name: "x", Type: "list", StringValue: []string{"a"}}, nil},
+	} {
+		err := c.param.Validate()
+		if c.expected == nil {
+			if err == nil {
+				t.Errorf("expected error for %v", c.param)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+		} else if !reflect.DeepEqual(c.param.StringValue, c.expected) {
+			t.Errorf("expected %v but %v", c.expected, c.param.StringValue)
+		}
+	}
+
+	req := JobScheduleRequest{
+		CronJobTrigger: &CronJobTrigger{CronString: "0 0 2 * * ?"},
+		JobParameters:  []JobParameter{{Name: "d", Type: "date", StringValue: []string{"2017-10-25"}}},
+	}
+	if err := req.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if req.JobParameters[0].StringValue[0] != date {
+		t.Errorf("the date of the request should be normalized: %s", req.JobParameters[0].StringValue[0])
+	}
+}
@@ -1,7 +1,9 @@
 package client
 
 import (
+	"fmt"
 	"sort"
+	"strings"
 	"time"
 )
 
@@ -9,8 +11,15 @@ import (
 type Status interface {
 	Print(w *IndentWriter)
 	IsFinished() bool
+	IsFailed() bool
+	PrintFailureSummary(w *IndentWriter)
+	GetName() string
+	GetBaseStatus() *BaseStatus
 }
 
+// maxErrorLines is the max number of the error lines printed in the failure summary.
+const maxErrorLines = 3
+
 // StepStatus represents the status of steps.
 type StepStatus struct {
 	Name              string  `xml:"stepname"`
@@ -104,12 +113,32 @@ type BaseStatus struct {
 	FirstLogLineNr    int    `xml:"first_log_line_nr"`
 	LastLogLineNr     int    `xml:"last_log_line_nr"`
 	Result            Result `xml:"result"`
-	LoggingString     string `xml:"logging_string"`
+	LoggingString     string `xml:"logging_string" json:"-"`
+}
+
+// GetBaseStatus gets the common part of the status.
+func (s *BaseStatus) GetBaseStatus() *BaseStatus {
+	return s
 }
 
 // IsFinished check if the job has finished.
 func (s *BaseStatus) IsFinished() bool {
-	return s.StatusDescription == "Finished"
+	return strings.HasPrefix(s.StatusDescription, "Finished") || strings.HasPrefix(s.StatusDescription, "Stopped")
+}
+
+// IsFailed checks if the job has failed.
+func (s *BaseStatus) IsFailed() bool {
+	return s.ErrorDescription != "" ||
+		s.Result.Errors > 0 ||
+		strings.Contains(s.StatusDescription, "errors") ||
+		strings.HasPrefix(s.StatusDescription, "Stopped")
+}
+
+func (s *BaseStatus) printFailureSummary(writer *IndentWriter, name string) {
+	writer.Printf("Failed: %s (%s)\n", name, s.StatusDescription)
+	if s.ErrorDescription != "" {
+		writer.Printf("Error : %s\n", s.ErrorDescription)
+	}
 }
 
 // ParseLogDate parses log
@@ -146,6 +175,11 @@ type TransformationStatus struct {
 	StepStatusList StepStatusList `xml:"stepstatuslist"`
 }
 
+// GetName gets the name of the transformation.
+func (t *TransformationStatus) GetName() string {
+	return t.Name
+}
+
 // Print the status of the transformation.
 func (t *TransformationStatus) Print(writer *IndentWriter) {
 	t.BaseStatus.print(writer, t.Name)
@@ -157,6 +191,35 @@ func (t *TransformationStatus) Print(writer *IndentWriter) {
 	writer.DecrementLevel()
 }
 
+// IsFailed checks if the transformation has failed.
+func (t *TransformationStatus) IsFailed() bool {
+	return t.BaseStatus.IsFailed() || len(t.FailedSteps()) > 0
+}
+
+// FailedSteps gets the steps which have errors or have been stopped.
+func (t *TransformationStatus) FailedSteps() []StepStatus {
+	var steps []StepStatus
+	for _, s := range t.StepStatusList.List {
+		if s.Errors > 0 || s.StatusDescription == "Stopped" {
+			steps = append(steps, s)
+		}
+	}
+	return steps
+}
+
+// PrintFailureSummary prints the failed steps and their first error lines.
+func (t *TransformationStatus) PrintFailureSummary(writer *IndentWriter) {
+	t.BaseStatus.printFailureSummary(writer, t.Name)
+	for _, s := range t.FailedSteps() {
+		writer.Printf("Step  : %s (%s, errors=%d)\n", s.Name, s.StatusDescription, s.Errors)
+		writer.IncrementLevel()
+		for _, line := range findErrorLines(t.LoggingString, fmt.Sprintf("%s.%d", s.Name, s.Copy), maxErrorLines) {
+			writer.Println(line)
+		}
+		writer.DecrementLevel()
+	}
+}
+
 // TransformationStatusList represents the status list of transformations.
 type TransformationStatusList struct {
 	List []TransformationStatus `xml:"transstatus"`
@@ -168,11 +231,26 @@ type JobStatus struct {
 	Name string `xml:"jobname"`
 }
 
+// GetName gets the name of the job.
+func (t *JobStatus) GetName() string {
+	return t.Name
+}
+
 // Print the status of the transformation.
 func (t *JobStatus) Print(writer *IndentWriter) {
 	t.BaseStatus.print(writer, t.Name)
 }
 
+// PrintFailureSummary prints the first error lines of the job.
+func (t *JobStatus) PrintFailureSummary(writer *IndentWriter) {
+	t.BaseStatus.printFailureSummary(writer, t.Name)
+	writer.IncrementLevel()
+	for _, line := range findErrorLines(t.LoggingString, "", maxErrorLines) {
+		writer.Println(line)
+	}
+	writer.DecrementLevel()
+}
+
 // JobStatusList represents the status list of the jobs.
 type JobStatusList struct {
 	List []JobStatus `xml:"jobstatus"`
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// TimelineEntry represents an execution of a job entry.
+type TimelineEntry struct {
+	Job      string
+	Name     string
+	Depth    int
+	Parallel bool
+	Start    time.Time
+	End      time.Time
+	Result   string
+}
+
+// IsFinished checks if the job entry has finished.
+func (e *TimelineEntry) IsFinished() bool {
+	return !e.End.IsZero()
+}
+
+// Duration gets the duration of the job entry.
+func (e *TimelineEntry) Duration() time.Duration {
+	return e.End.Sub(e.Start)
+}
+
+var (
+	startingEntryPattern = regexp.MustCompile(`^Starting entry \[(.*)\]$`)
+	finishedEntryPattern = regexp.MustCompile(`^Finished job entry \[(.*)\] \(result=\[(.*)\]\)$`)
+	parallelEntryPattern = regexp.MustCompile(`^Launched job entry \[(.*)\] in parallel\.$`)
+)
+
+// ParseJobTimeline parses the job entry start/finish events from the logging string of the job.
+// The entries which have not finished are ended at the last log time.
+func ParseJobTimeline(loggingString string) []TimelineEntry {
+	var entries []*TimelineEntry
+	var last time.Time
+	jobDepth := map[string]int{}
+	parallel := map[string]bool{}
+	findOpenEntry := func(job string, name string) *TimelineEntry {
+		for i := len(entries) - 1; i >= 0; i-- {
+			e := entries[i]
+			if !e.IsFinished() && (job == "" || e.Job == job) && (name == "" || e.Name == name) {
+				return e
+			}
+		}
+		return nil
+	}
+	for _, line := range strings.Split(loggingString, "\n") {
+		m := logLinePattern.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m == nil {
+			continue
+		}
+		t, err := time.ParseInLocation("2006/01/02 15:04:05", m[0][:19], time.Local)
+		if err != nil {
+			continue
+		}
+		last = t
+		job, message := m[1], m[2]
+		depth, ok := jobDepth[job]
+		if !ok {
+			if e := findOpenEntry("", ""); e != nil {
+				depth = e.Depth + 1
+			}
+			jobDepth[job] = depth
+		}
+		if s := parallelEntryPattern.FindStringSubmatch(message); s != nil {
+			parallel[job+"\x00"+s[1]] = true
+		} else if s := startingEntryPattern.FindStringSubmatch(message); s != nil {
+			if _, ok := jobDepth[s[1]]; !ok {
+				jobDepth[s[1]] = depth + 1
+			}
+			entries = append(entries, &TimelineEntry{
+				Job:      job,
+				Name:     s[1],
+				Depth:    depth,
+				Parallel: parallel[job+"\x00"+s[1]],
+				Start:    t,
+			})
+		} else if s := finishedEntryPattern.FindStringSubmatch(message); s != nil {
+			if e := findOpenEntry(job, s[1]); e != nil {
+				e.End = t
+				e.Result = s[2]
+			}
+		}
+	}
+	timeline := make([]TimelineEntry, len(entries))
+	for i, e := range entries {
+		if !e.IsFinished() {
+			e.End = last
+		}
+		timeline[i] = *e
+	}
+	return timeline
+}
@@ -0,0 +1,38 @@
+package client
+
+import "testing"
+
+const testJobLog = `2017/10/25 10:00:00 - main - Start of job execution
+2017/10/25 10:00:00 - main - Starting entry [load]
+2017/10/25 10:00:00 - load - Starting entry [extract]
+2017/10/25 10:00:10 - load - Finished job entry [extract] (result=[true])
+2017/10/25 10:00:10 - load - Launched job entry [transform] in parallel.
+2017/10/25 10:00:10 - load - Starting entry [transform]
+2017/10/25 10:00:30 - load - Finished job entry [transform] (result=[false])
+2017/10/25 10:00:30 - main - Finished job entry [load] (result=[false])
+2017/10/25 10:00:31 - main - Starting entry [notify]`
+
+func TestParseJobTimeline(t *testing.T) {
+	timeline := ParseJobTimeline(testJobLog)
+	if len(timeline) != 4 {
+		t.Fatalf("expected 4 entries but %d entries", len(timeline))
+	}
+	expected := []struct {
+		name     string
+		depth    int
+		seconds  float64
+		result   string
+		parallel bool
+	}{
+		{"load", 0, 30, "false", false},
+		{"extract", 1, 10, "true", false},
+		{"transform", 1, 20, "false", true},
+		{"notify", 0, 0, "", false},
+	}
+	for i, e := range expected {
+		actual := timeline[i]
+		if actual.Name != e.name || actual.Depth != e.depth || actual.Duration().Seconds() != e.seconds || actual.Result != e.result || actual.Parallel != e.parallel {
+			t.Errorf("expected %v but %v", e, actual)
+		}
+	}
+}
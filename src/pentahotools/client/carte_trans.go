@@ -54,16 +54,19 @@ func (c *TransformationClient) GetStatus(id string, name string, from int) (Stat
 }
 
 // Run runs the transformation.
-func (c *TransformationClient) Run(file string, level LogLevel) (string, error) {
-	c.logger.Debug("RunTrans", zap.String("file", file))
+func (c *TransformationClient) Run(file string, level LogLevel, params map[string]string) (string, error) {
+	c.logger.Debug("RunTrans", zap.String("file", file), zap.Any("params", params))
 	if strings.HasSuffix(file, ".ktr") {
 		file = file[0 : len(file)-4]
 	}
+	formData := map[string]string{}
+	for name, value := range params {
+		formData[name] = value
+	}
+	formData["trans"] = file
+	formData["level"] = string(level)
 	resp, err := c.client.R().
-		SetFormData(map[string]string{
-			"trans": file,
-			"level": string(level),
-		}).
+		SetFormData(formData).
 		SetHeader("Accept", "*/*").
 		Post("kettle/runTrans/")
 	switch resp.StatusCode() {
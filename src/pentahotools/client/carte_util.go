@@ -28,3 +28,26 @@ func decodeLoggingString(loggingString string) string {
 	decoded, _ := ioutil.ReadAll(gzipReader)
 	return string(decoded)
 }
+
+// logLinePattern matches a line of Kettle logs. (e.g., "2017/10/25 10:00:00 - Table input.0 - ERROR (version 7.1.0.0-12) : message")
+var logLinePattern = regexp.MustCompile(`^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2} - (.+?) - (.*)$`)
+
+// findErrorLines finds the error lines of the subject(step or job entry) from the logging string.
+// If subject is empty, finds the error lines of all subjects.
+func findErrorLines(loggingString string, subject string, max int) []string {
+	var lines []string
+	for _, line := range strings.Split(loggingString, "\n") {
+		if len(lines) >= max {
+			break
+		}
+		m := logLinePattern.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m == nil || !strings.HasPrefix(m[2], "ERROR") {
+			continue
+		}
+		if subject != "" && m[1] != subject {
+			continue
+		}
+		lines = append(lines, m[0])
+	}
+	return lines
+}
@@ -0,0 +1,31 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testTransformationLog = `2017/10/25 10:00:00 - trans - Dispatching started for transformation [trans]
+2017/10/25 10:00:01 - Table input.0 - ERROR (version 7.1.0.0-12, build 1 from 2017-05-16 17.18.02 by buildguy) : Unexpected error
+2017/10/25 10:00:01 - Table input.0 - ERROR (version 7.1.0.0-12, build 1 from 2017-05-16 17.18.02 by buildguy) : org.pentaho.di.core.exception.KettleDatabaseException:
+	at org.pentaho.di.core.database.Database.openQuery(Database.java:1776)
+2017/10/25 10:00:01 - Output.0 - Finished processing (I=0, O=0, R=0, W=0, U=0, E=0)
+2017/10/25 10:00:01 - trans - ERROR (version 7.1.0.0-12, build 1 from 2017-05-16 17.18.02 by buildguy) : Errors detected!`
+
+func TestFindErrorLinesOfStep(t *testing.T) {
+	lines := findErrorLines(testTransformationLog, "Table input.0", 3)
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines but %d lines: %v", len(lines), lines)
+	}
+	if lines := findErrorLines(testTransformationLog, "Output.0", 3); len(lines) != 0 {
+		t.Errorf("expected no lines but %v", lines)
+	}
+}
+
+func TestFindErrorLinesMax(t *testing.T) {
+	lines := findErrorLines(testTransformationLog, "", 1)
+	expected := []string{"2017/10/25 10:00:01 - Table input.0 - ERROR (version 7.1.0.0-12, build 1 from 2017-05-16 17.18.02 by buildguy) : Unexpected error"}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("expected %v but %v", expected, lines)
+	}
+}
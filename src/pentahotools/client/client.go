@@ -2,6 +2,7 @@ package client
 
 import (
 	"fmt"
+	"net/http"
 
 	"go.uber.org/zap"
 
@@ -14,9 +15,12 @@ type Client struct {
 	User                 string
 	Password             string
 	client               *resty.Client
+	httpClient           *http.Client
 	JobClient            CarteClient
 	TransformationClient CarteClient
 	Logger               Logger
+	// ShowProgress shows the progress bars of the file transfers.
+	ShowProgress bool
 }
 
 // NewClient create new instance of Client.
@@ -29,7 +33,11 @@ func NewClient(url string, user string, password string) Client {
 		Password: password,
 		Logger:   logger,
 	}
+	// the file transfers share the transport with the API client to stream the large content.
+	transport := newTransport()
+	client.httpClient = &http.Client{Transport: transport}
 	client.client = resty.New().
+		SetTransport(transport).
 		SetHostURL(url).
 		SetBasicAuth(user, password).
 		SetDisableWarn(true)
@@ -0,0 +1,298 @@
+package client
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var monthNames = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+
+var dayOfWeekNames = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+
+// cronField is the specification of a field of the cron expression.
+type cronField struct {
+	name  string
+	min   int
+	max   int
+	names []string
+	// special matches the field specific special values. (e.g., "L", "15W", "MON#1")
+	special *regexp.Regexp
+	// value gets the value of the field from the time.
+	value func(t time.Time) int
+}
+
+var cronFields = []cronField{
+	{"seconds", 0, 59, nil, nil, time.Time.Second},
+	{"minutes", 0, 59, nil, nil, time.Time.Minute},
+	{"hours", 0, 23, nil, nil, time.Time.Hour},
+	{"day-of-month", 1, 31, nil, regexp.MustCompile(`^(L(-\d+)?|LW|\d+W)$`), time.Time.Day},
+	{"month", 1, 12, monthNames, nil, func(t time.Time) int { return int(t.Month()) }},
+	{"day-of-week", 1, 7, dayOfWeekNames, regexp.MustCompile(`^(L|\w+L|\w+#[1-5])$`), func(t time.Time) int { return int(t.Weekday()) + 1 }},
+	{"year", 1970, 2099, nil, nil, time.Time.Year},
+}
+
+// cronMatcher checks if the time matches a field of the cron expression.
+type cronMatcher func(t time.Time) bool
+
+// CronSchedule is a parsed Quartz cron expression.
+type CronSchedule struct {
+	expression string
+	matchers   []cronMatcher
+}
+
+// ParseCronExpression parses the Quartz cron expression.
+// (e.g., "0 30 2 ? * MON-FRI")
+func ParseCronExpression(expression string) (*CronSchedule, error) {
+	fields := strings.Fields(expression)
+	if len(fields) != 6 && len(fields) != 7 {
+		return nil, fmt.Errorf("cron expression must have 6 or 7 fields: %s", expression)
+	}
+	if (fields[3] == "?") == (fields[5] == "?") {
+		return nil, fmt.Errorf("specify '?' for either day-of-month or day-of-week: %s", expression)
+	}
+	schedule := &CronSchedule{expression: expression}
+	for i, field := range fields {
+		matcher, err := cronFields[i].parse(field)
+		if err != nil {
+			return nil, err
+		}
+		schedule.matchers = append(schedule.matchers, matcher)
+	}
+	return schedule, nil
+}
+
+// ValidateCronExpression validates the Quartz cron expression.
+func ValidateCronExpression(expression string) error {
+	_, err := ParseCronExpression(expression)
+	return err
+}
+
+// FireTimes gets the fire times in the period [from, to).
+// The fire times are computed in the minute resolution and the local time zone.
+func (s *CronSchedule) FireTimes(from time.Time, to time.Time) []time.Time {
+	var times []time.Time
+	// the seconds field is ignored.
+	matchers := s.matchers[1:]
+	for t := from.Truncate(time.Minute); t.Before(to); t = t.Add(time.Minute) {
+		if t.Before(from) {
+			continue
+		}
+		matched := true
+		for _, m := range matchers {
+			if !m(t) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			times = append(times, t)
+		}
+	}
+	return times
+}
+
+func (s *CronSchedule) String() string {
+	return s.expression
+}
+
+func (f *cronField) parse(field string) (cronMatcher, error) {
+	if field == "?" {
+		if f.name == "day-of-month" || f.name == "day-of-week" {
+			return func(t time.Time) bool { return true }, nil
+		}
+		return nil, fmt.Errorf("'?' is not allowed for %s", f.name)
+	}
+	var matchers []cronMatcher
+	for _, item := range strings.Split(field, ",") {
+		var matcher cronMatcher
+		var err error
+		if f.special != nil && f.special.MatchString(strings.ToUpper(item)) {
+			matcher, err = f.parseSpecial(strings.ToUpper(item))
+		} else {
+			matcher, err = f.parseItem(item)
+		}
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, matcher)
+	}
+	return func(t time.Time) bool {
+		for _, m := range matchers {
+			if m(t) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func (f *cronField) parseSpecial(item string) (cronMatcher, error) {
+	switch {
+	case f.name == "day-of-month" && item == "LW":
+		return func(t time.Time) bool {
+			return t.Day() == nearestWeekday(t, lastDayOfMonth(t))
+		}, nil
+	case f.name == "day-of-month" && strings.HasPrefix(item, "L"):
+		offset := 0
+		if len(item) > 1 {
+			offset, _ = strconv.Atoi(item[2:])
+		}
+		return func(t time.Time) bool { return t.Day() == lastDayOfMonth(t)-offset }, nil
+	case f.name == "day-of-month":
+		day, err := f.parseValue(strings.TrimSuffix(item, "W"))
+		if err != nil {
+			return nil, err
+		}
+		return func(t time.Time) bool { return t.Day() == nearestWeekday(t, day) }, nil
+	case item == "L":
+		return func(t time.Time) bool { return t.Weekday() == time.Saturday }, nil
+	case strings.HasSuffix(item, "L"):
+		dayOfWeek, err := f.parseValue(strings.TrimSuffix(item, "L"))
+		if err != nil {
+			return nil, err
+		}
+		return func(t time.Time) bool {
+			return f.value(t) == dayOfWeek && t.Day()+7 > lastDayOfMonth(t)
+		}, nil
+	default:
+		parts := strings.SplitN(item, "#", 2)
+		dayOfWeek, err := f.parseValue(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		week, _ := strconv.Atoi(parts[1])
+		return func(t time.Time) bool {
+			return f.value(t) == dayOfWeek && (t.Day()-1)/7+1 == week
+		}, nil
+	}
+}
+
+func (f *cronField) parseItem(item string) (cronMatcher, error) {
+	rangePart := item
+	step := 1
+	if i := strings.Index(item, "/"); i >= 0 {
+		rangePart = item[:i]
+		var err error
+		step, err = strconv.Atoi(item[i+1:])
+		if err != nil || step <= 0 || step > f.max {
+			return nil, fmt.Errorf("invalid increment of %s: %s", f.name, item)
+		}
+	}
+	from, to := f.min, f.max
+	if rangePart != "*" {
+		bounds := strings.SplitN(rangePart, "-", 2)
+		var err error
+		if from, err = f.parseValue(bounds[0]); err != nil {
+			return nil, err
+		}
+		to = from
+		if len(bounds) == 2 {
+			if to, err = f.parseValue(bounds[1]); err != nil {
+				return nil, err
+			}
+		} else if strings.Contains(item, "/") {
+			to = f.max
+		}
+	}
+	values := map[int]bool{}
+	size := f.max - f.min + 1
+	for i := 0; i <= (to-from+size)%size; i += step {
+		values[f.min+(from-f.min+i)%size] = true
+	}
+	return func(t time.Time) bool { return values[f.value(t)] }, nil
+}
+
+// parseValue parses a numeric or named value of the field.
+func (f *cronField) parseValue(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.ToUpper(s) == name {
+			return f.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid value of %s: %s (%d-%d)", f.name, s, f.min, f.max)
+	}
+	return v, nil
+}
+
+func lastDayOfMonth(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+}
+
+// nearestWeekday gets the nearest weekday to the day in the month of t.
+func nearestWeekday(t time.Time, day int) int {
+	last := lastDayOfMonth(t)
+	if day > last {
+		day = last
+	}
+	switch time.Date(t.Year(), t.Month(), day, 0, 0, 0, 0, t.Location()).Weekday() {
+	case time.Saturday:
+		if day == 1 {
+			return day + 2
+		}
+		return day - 1
+	case time.Sunday:
+		if day == last {
+			return day - 2
+		}
+		return day + 1
+	}
+	return day
+}
+
+// ParseDaysOfWeek parses the days of week for ComplexJobTrigger. (e.g., "MON-FRI", "SUN,SAT")
+// The days are numbered from 0(Sunday) to 6(Saturday).
+func ParseDaysOfWeek(s string) ([]int, error) {
+	return parseIntList(s, 0, 6, dayOfWeekNames)
+}
+
+// ParseMonthsOfYear parses the months for ComplexJobTrigger. (e.g., "JAN,APR,JUL,OCT")
+// The months are numbered from 0(January) to 11(December).
+func ParseMonthsOfYear(s string) ([]int, error) {
+	return parseIntList(s, 0, 11, monthNames)
+}
+
+// ParseDaysOfMonth parses the days of month for ComplexJobTrigger. (e.g., "1,15")
+func ParseDaysOfMonth(s string) ([]int, error) {
+	return parseIntList(s, 1, 31, nil)
+}
+
+// ParseWeeksOfMonth parses the weeks of month for ComplexJobTrigger. (e.g., "1", "2,LAST")
+// The weeks are numbered from 0(first) to 4(last).
+func ParseWeeksOfMonth(s string) ([]int, error) {
+	weeks, err := parseIntList(strings.Replace(strings.ToUpper(s), "LAST", "5", -1), 1, 5, nil)
+	for i := range weeks {
+		weeks[i]--
+	}
+	return weeks, err
+}
+
+// parseIntList parses the comma separated values and ranges.
+func parseIntList(s string, min int, max int, names []string) ([]int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	f := cronField{name: "value", min: min, max: max, names: names}
+	var values []int
+	for _, item := range strings.Split(s, ",") {
+		bounds := strings.SplitN(strings.TrimSpace(item), "-", 2)
+		from, err := f.parseValue(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		to := from
+		if len(bounds) == 2 {
+			if to, err = f.parseValue(bounds[1]); err != nil {
+				return nil, err
+			}
+		}
+		for v := from; v <= to; v++ {
+			values = append(values, v)
+		}
+	}
+	return values, nil
+}
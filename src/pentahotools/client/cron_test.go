@@ -0,0 +1,85 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValidateCronExpression(t *testing.T) {
+	valid := []string{
+		"0 30 2 ? * MON-FRI",
+		"0 0 3 ? * 2#1",
+		"0 0 3 ? * MON#1",
+		"0 0/15 * * * ?",
+		"0 0 12 L * ?",
+		"0 0 12 15W * ?",
+		"0 0 12 ? JAN,JUL 6L 2020",
+	}
+	for _, expression := range valid {
+		if err := ValidateCronExpression(expression); err != nil {
+			t.Errorf("expected valid but %v", err)
+		}
+	}
+	invalid := []string{
+		"0 30 2 * *",
+		"0 60 2 ? * MON-FRI",
+		"0 30 2 * * MON-FRI",
+		"0 30 2 ? * ?",
+		"0 30 2 ? * FOO",
+		"? 30 2 ? * MON",
+		"0 0/0 2 ? * MON",
+	}
+	for _, expression := range invalid {
+		if err := ValidateCronExpression(expression); err == nil {
+			t.Errorf("expected invalid: %s", expression)
+		}
+	}
+}
+
+func TestParseComplexTriggerValues(t *testing.T) {
+	days, err := ParseDaysOfWeek("MON-FRI")
+	if err != nil || !reflect.DeepEqual(days, []int{1, 2, 3, 4, 5}) {
+		t.Errorf("unexpected days of week: %v, %v", days, err)
+	}
+	months, err := ParseMonthsOfYear("jan,dec")
+	if err != nil || !reflect.DeepEqual(months, []int{0, 11}) {
+		t.Errorf("unexpected months: %v, %v", months, err)
+	}
+	weeks, err := ParseWeeksOfMonth("1,last")
+	if err != nil || !reflect.DeepEqual(weeks, []int{0, 4}) {
+		t.Errorf("unexpected weeks of month: %v, %v", weeks, err)
+	}
+	if _, err := ParseDaysOfMonth("0"); err == nil {
+		t.Error("expected error for day of month 0")
+	}
+}
+
+func TestCronFireTimes(t *testing.T) {
+	// 2017-10-01 is Sunday.
+	from := time.Date(2017, 10, 1, 0, 0, 0, 0, time.Local)
+	to := from.AddDate(0, 1, 0)
+	for expression, expected := range map[string][]string{
+		"0 30 2 ? * MON-FRI": {"2017-10-02 02:30", "2017-10-03 02:30", "2017-10-04 02:30", "2017-10-05 02:30", "2017-10-06 02:30"},
+		"0 0 3 ? * MON#1":    {"2017-10-02 03:00"},
+		"0 0 3 ? * 6L":       {"2017-10-27 03:00"},
+		"0 0 3 L * ?":        {"2017-10-31 03:00"},
+		"0 0 3 1W * ?":       {"2017-10-02 03:00"},
+		"0 0 22-23 15 * ?":   {"2017-10-15 22:00", "2017-10-15 23:00"},
+	} {
+		schedule, err := ParseCronExpression(expression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var actual []string
+		for _, fireTime := range schedule.FireTimes(from, to) {
+			actual = append(actual, fireTime.Format("2006-01-02 15:04"))
+		}
+		if len(expected) < len(actual) {
+			actual = actual[:len(expected)]
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s: expected %v but %v", expression, expected, actual)
+		}
+	}
+}
@@ -1,31 +1,36 @@
 package client
 
 import (
+	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
 	"path/filepath"
 
-	"net/url"
-
 	"encoding/xml"
 
 	"html"
 
 	"github.com/pkg/errors"
+	resty "gopkg.in/resty.v0"
 )
 
 // Backup backups whole of the pentaho.
 func (c *Client) Backup(output string) error {
 	c.Logger.Debug("Backup", zap.String("output", output))
-	resp, err := c.client.R().
-		SetOutput(output).
-		Get(fmt.Sprintf("api/repo/files/backup"))
-	switch resp.StatusCode() {
+	resp, err := c.download("backup", "api/repo/files/backup", nil, nil, output)
+	if err != nil {
+		return err
+	}
+	switch resp.StatusCode {
 	case 200:
 		return nil
 	case 403:
@@ -33,10 +38,7 @@ func (c *Client) Backup(output string) error {
 	case 500:
 		return errors.New("Failure to complete the export")
 	default:
-		if err != nil {
-			return err
-		}
-		return fmt.Errorf("Unknown error. statusCode=%d", resp.StatusCode())
+		return fmt.Errorf("Unknown error. statusCode=%d", resp.StatusCode)
 	}
 }
 
@@ -64,7 +66,11 @@ func (c *Client) Restore(input string, overwrite bool) error {
 	}
 }
 
+// ErrNotFound is returned when the file does not exist in the repository.
+var ErrNotFound = errors.New("file not found")
+
 // Tree list the children of the specified path.
+// It returns ErrNotFound if the path does not exist.
 func (c *Client) Tree(path string, depth int, showHidden bool) (*FileEntry, error) {
 	c.Logger.Debug("Tree", zap.String("path", path), zap.Int("depth", depth), zap.Bool("showHidden", showHidden))
 	var root FileEntry
@@ -79,7 +85,31 @@ func (c *Client) Tree(path string, depth int, showHidden bool) (*FileEntry, erro
 	case 200:
 		return &root, nil
 	case 404:
-		return nil, errors.New("invalid parameters")
+		return nil, ErrNotFound
+	case 500:
+		return nil, errors.New("server error")
+	default:
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("Unknown error. statusCode=%d", resp.StatusCode())
+	}
+}
+
+// GetGeneratedContent lists the files generated from the file by the scheduler.
+func (c *Client) GetGeneratedContent(path string) ([]FileInfo, error) {
+	c.Logger.Debug("GetGeneratedContent", zap.String("path", path))
+	var files fileInfoList
+	resp, err := c.client.R().
+		SetHeader("Accept", "application/json").
+		SetResult(&files).
+		Get(fmt.Sprintf("api/repo/files/%s/generatedContent", strings.Replace(path, "/", ":", -1)))
+
+	switch resp.StatusCode() {
+	case 200:
+		return files.list()
+	case 404:
+		return nil, errors.New("file not found")
 	case 500:
 		return nil, errors.New("server error")
 	default:
@@ -90,6 +120,30 @@ func (c *Client) Tree(path string, depth int, showHidden bool) (*FileEntry, erro
 	}
 }
 
+// fileInfoList is the response of the APIs which return a list of files.
+// The file is serialized as an object instead of an array if there is only one file.
+type fileInfoList struct {
+	RepositoryFileDto json.RawMessage `json:"repositoryFileDto"`
+}
+
+func (l *fileInfoList) list() ([]FileInfo, error) {
+	if len(l.RepositoryFileDto) == 0 {
+		return nil, nil
+	}
+	if l.RepositoryFileDto[0] == '{' {
+		var file FileInfo
+		if err := json.Unmarshal(l.RepositoryFileDto, &file); err != nil {
+			return nil, err
+		}
+		return []FileInfo{file}, nil
+	}
+	var files []FileInfo
+	if err := json.Unmarshal(l.RepositoryFileDto, &files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
 // GetACL gets the access control list of file.
 func (c *Client) GetACL(path string) (*ACL, error) {
 	c.Logger.Debug("GetACL", zap.String("path", path))
@@ -118,19 +172,20 @@ func (c *Client) GetACL(path string) (*ACL, error) {
 
 // Ac represents an access control
 type Ac struct {
-	Modifiable    string
-	Permissions   string
-	Recipient     string
-	RecipientType string
+	Modifiable    string      `json:"modifiable"`
+	Permissions   jsonStrings `json:"permissions"`
+	Recipient     string      `json:"recipient"`
+	RecipientType string      `json:"recipientType"`
 }
 
 // ACL represents an access control list
+// Aces are the effective entries including the inherited ones if EntriesInheriting is "true".
 type ACL struct {
-	Aces              []Ac
-	EntriesInheriting string
-	ID                string
-	Owner             string
-	OwnerType         string
+	Aces              AcList `json:"aces"`
+	EntriesInheriting string `json:"entriesInheriting"`
+	ID                string `json:"id"`
+	Owner             string `json:"owner"`
+	OwnerType         string `json:"ownerType"`
 }
 
 // PutFile put the file to the repository.
@@ -141,15 +196,11 @@ func (c *Client) PutFile(file string, destination string) error {
 		_, filename := filepath.Split(file)
 		destination = destination + filename
 	}
-	data, err := ioutil.ReadFile(file)
+	statusCode, err := c.upload("PUT", fmt.Sprintf("api/repo/files/%s", strings.Replace(destination, "/", ":", -1)), file)
 	if err != nil {
 		return err
 	}
-	resp, err := c.client.R().
-		SetBody(data).
-		Put(fmt.Sprintf("api/repo/files/%s", strings.Replace(destination, "/", ":", -1)))
-
-	switch resp.StatusCode() {
+	switch statusCode {
 	case 200:
 		return nil
 	case 403:
@@ -157,10 +208,7 @@ func (c *Client) PutFile(file string, destination string) error {
 	case 500:
 		return errors.New("server error")
 	default:
-		if err != nil {
-			return err
-		}
-		return fmt.Errorf("Unknown error. statusCode=%d", resp.StatusCode())
+		return fmt.Errorf("Unknown error. statusCode=%d", statusCode)
 	}
 }
 
@@ -238,20 +286,38 @@ func (c *Client) GetFileContent(repositoryPath string) ([]byte, error) {
 
 // getFile gets file from the repository
 func (c *Client) getFile(repositoryPath string, destination string) ([]byte, error) {
-	c.Logger.Debug("getFile", zap.String("repositoryPath", repositoryPath), zap.String("destination", destination))
-	isCatMode := destination == ""
-	req := c.client.R()
-	if !isCatMode {
-		req.SetOutput(destination)
+	return c.getFileVersion(repositoryPath, "", destination)
+}
+
+// getFileVersion gets the version of the file from the repository.
+// The latest version is got if versionID is empty.
+func (c *Client) getFileVersion(repositoryPath string, versionID string, destination string) ([]byte, error) {
+	c.Logger.Debug("getFile", zap.String("repositoryPath", repositoryPath), zap.String("versionID", versionID), zap.String("destination", destination))
+	apiPath := fmt.Sprintf("api/repo/files/%s", strings.Replace(repositoryPath, "/", ":", -1))
+	query := url.Values{}
+	if versionID != "" {
+		if _, err := c.checkVersion(repositoryPath, versionID); err != nil {
+			return nil, err
+		}
+		query.Set("versionId", versionID)
+	}
+	var statusCode int
+	var body []byte
+	var err error
+	if destination == "" {
+		var resp *resty.Response
+		resp, err = c.client.R().SetMultiValueQueryParams(query).Get(apiPath)
+		statusCode, body = resp.StatusCode(), resp.Body()
+	} else {
+		var resp *http.Response
+		if resp, err = c.download(repositoryPath, apiPath, query, nil, destination); err == nil {
+			statusCode = resp.StatusCode
+		}
 	}
-	resp, err := req.Get(fmt.Sprintf("api/repo/files/%s", strings.Replace(repositoryPath, "/", ":", -1)))
 
-	switch resp.StatusCode() {
+	switch statusCode {
 	case 200:
-		if isCatMode {
-			return resp.Body(), nil
-		}
-		return nil, nil
+		return body, nil
 	case 403:
 		return nil, errors.New("Failure to create the file due to permissions, file already exists, or invalid path id")
 	case 404:
@@ -262,7 +328,7 @@ func (c *Client) getFile(repositoryPath string, destination string) ([]byte, err
 		if err != nil {
 			return nil, err
 		}
-		return nil, fmt.Errorf("Unknown error. statusCode=%d", resp.StatusCode())
+		return nil, fmt.Errorf("Unknown error. statusCode=%d", statusCode)
 	}
 }
 
@@ -275,14 +341,16 @@ func (c *Client) DownloadFile(repositoryPath string, destination string, withMan
 		return "", err
 	}
 	defer helper.Clean()
-	resp, err := c.client.R().
-		SetOutput(helper.GetTemporaryFilePath()).
-		SetHeader("User-Agent", "Firefox").
-		SetQueryParam("withManifest", strconv.FormatBool(withManifest)).
-		Get(fmt.Sprintf("api/repo/files/%s/download", strings.Replace(repositoryPath, "/", ":", -1)))
-	switch resp.StatusCode() {
+	resp, err := c.download(repositoryPath, fmt.Sprintf("api/repo/files/%s/download", strings.Replace(repositoryPath, "/", ":", -1)),
+		url.Values{"withManifest": []string{strconv.FormatBool(withManifest)}},
+		http.Header{"User-Agent": []string{"Firefox"}},
+		helper.GetTemporaryFilePath())
+	if err != nil {
+		return "", err
+	}
+	switch resp.StatusCode {
 	case 200:
-		return helper.MoveTemporaryFileToDestination(resp)
+		return helper.MoveDownloadedFileToDestination(resp.Header)
 	case 403:
 		return "", errors.New("Failure to create the file due to permissions, file already exists, or invalid path id")
 	case 404:
@@ -290,45 +358,7 @@ func (c *Client) DownloadFile(repositoryPath string, destination string, withMan
 	case 500:
 		return "", errors.New("server error")
 	default:
-		if err != nil {
-			return "", err
-		}
-		return "", fmt.Errorf("Unknown error. statusCode=%d", resp.StatusCode())
-	}
-}
-
-// ImportFile imports a file to the directory in the repository.
-func (c *Client) ImportFile(file string, importDir string, params *ImportParameters) error {
-	c.Logger.Debug("ImportFile", zap.String("file", file), zap.String("importDir", importDir), zap.String("params", fmt.Sprint(params)))
-	_, filename := filepath.Split(file)
-	resp, err := c.client.R().
-		SetFiles(map[string]string{
-			"fileUpload": filename,
-		}).
-		SetMultiValueFormData(url.Values{
-			"overwriteFile":           []string{strconv.FormatBool(params.OverwriteFile)},
-			"logLevel":                []string{params.LogLevel},
-			"retainOwnership":         []string{strconv.FormatBool(params.RetainOwnership)},
-			"fileNameOverride":        []string{params.FileNameOverride},
-			"importDir":               []string{importDir},
-			"charSet":                 []string{params.Charset},
-			"applyAclPermissions":     []string{strconv.FormatBool(params.ApplyACLPermissions)},
-			"overwriteAclPermissions": []string{strconv.FormatBool(params.OverwriteACLPermissions)},
-		}).
-		Post("api/repo/files/import")
-	if err != nil {
-		return err
-	}
-	switch resp.StatusCode() {
-	case 200:
-		return nil
-	case 500:
-		return errors.New("server error")
-	default:
-		if err != nil {
-			return err
-		}
-		return fmt.Errorf("Unknown error. statusCode=%d", resp.StatusCode())
+		return "", fmt.Errorf("Unknown error. statusCode=%d", resp.StatusCode)
 	}
 }
 
@@ -345,14 +375,14 @@ func (c *Client) DeleteFilesPermanently(repositoryPaths ...string) error {
 }
 
 func (c *Client) deleteFile(permanent bool, repositoryPath ...string) error {
-	ids := make([]string, len(repositoryPath))
-	for i, path := range repositoryPath {
-		acl, err := c.GetACL(path)
-		if err != nil {
-			return errors.Wrap(err, "failed to get the ID of the deleting file:"+path)
-		}
-		ids[i] = acl.ID
+	ids, err := c.getFileIDs(repositoryPath...)
+	if err != nil {
+		return err
 	}
+	return c.deleteFileIDs(permanent, ids...)
+}
+
+func (c *Client) deleteFileIDs(permanent bool, ids ...string) error {
 	var apiPath string
 	if permanent {
 		apiPath = "api/repo/files/deletepermanent"
@@ -384,44 +414,164 @@ type FileEntry struct {
 
 // FileInfo represents a file information.
 type FileInfo struct {
-	ACLNode               string `json:"aclNode"`
-	CreatedDate           string `json:"createdDate"`
-	FileSize              string `json:"fileSize"`
-	Folder                string `json:"folder"`
-	Hidden                string `json:"hidden"`
-	ID                    string `json:"id"`
-	Locale                string `json:"locale"`
-	Locked                string `json:"locked"`
-	Name                  string `json:"name"`
-	NotSchedulable        string `json:"notSchedulable"`
-	OwnerType             string `json:"ownerType"`
-	Path                  string `json:"path"`
-	Title                 string `json:"title"`
-	VersionCommentEnabled string `json:"versionCommentEnabled"`
-	Versioned             string `json:"versioned"`
-	VersioningEnabled     string `json:"versioningEnabled"`
+	ACLNode                  string `json:"aclNode"`
+	CreatedDate              string `json:"createdDate"`
+	DeletedDate              string `json:"deletedDate"`
+	FileSize                 string `json:"fileSize"`
+	Folder                   string `json:"folder"`
+	Hidden                   string `json:"hidden"`
+	ID                       string `json:"id"`
+	LastModifiedDate         string `json:"lastModifiedDate"`
+	Locale                   string `json:"locale"`
+	Locked                   string `json:"locked"`
+	Name                     string `json:"name"`
+	NotSchedulable           string `json:"notSchedulable"`
+	OriginalParentFolderPath string `json:"originalParentFolderPath"`
+	OwnerType                string `json:"ownerType"`
+	Path                     string `json:"path"`
+	Title                    string `json:"title"`
+	VersionCommentEnabled    string `json:"versionCommentEnabled"`
+	Versioned                string `json:"versioned"`
+	VersioningEnabled        string `json:"versioningEnabled"`
+}
+
+// IsFolder checks if the file is a folder.
+func (f *FileInfo) IsFolder() bool {
+	return f.Folder == "true"
+}
+
+// IsHidden checks if the file is hidden.
+func (f *FileInfo) IsHidden() bool {
+	return f.Hidden == "true"
+}
+
+// IsLocked checks if the file is locked.
+func (f *FileInfo) IsLocked() bool {
+	return f.Locked == "true"
+}
+
+// Size gets the file size in bytes.
+func (f *FileInfo) Size() int64 {
+	size, _ := strconv.ParseInt(f.FileSize, 10, 64)
+	return size
+}
+
+// Created gets the created date of the file.
+// The zero time is returned if the date is not available.
+func (f *FileInfo) Created() time.Time {
+	return parseFileDate(f.CreatedDate)
+}
+
+// Modified gets the last modified date of the file.
+// The created date is returned if the file has never been modified.
+func (f *FileInfo) Modified() time.Time {
+	if f.LastModifiedDate == "" {
+		return f.Created()
+	}
+	return parseFileDate(f.LastModifiedDate)
+}
+
+// parseFileDate parses the date of the file in milliseconds since epoch.
+func parseFileDate(s string) time.Time {
+	ms, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
+
+// Walk calls the function for the entry and its descendants in depth-first order.
+// Returning filepath.SkipDir from the function skips the children of the entry.
+func (e *FileEntry) Walk(f func(entry *FileEntry) error) error {
+	if err := f(e); err != nil {
+		if err == filepath.SkipDir {
+			return nil
+		}
+		return err
+	}
+	for i := range e.Children {
+		if err := e.Children[i].Walk(f); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Print prints the file entry recursively
 func (e *FileEntry) Print() {
-	e.print(0)
+	e.Fprint(os.Stdout)
 }
 
-func (e *FileEntry) print(level int) {
-	indent := fmt.Sprintf(fmt.Sprintf("%%%ds", level*2), "")
-	fmt.Printf("%s%s (%s)\n", indent, e.File.Name, e.File.Path)
-	for _, entry := range e.Children {
-		entry.print(level + 1)
+// Fprint prints the file entry recursively as a tree.
+// The folders are suffixed with '/', and the files are followed by the size and the last modified date.
+func (e *FileEntry) Fprint(w io.Writer) {
+	fmt.Fprintln(w, e.describe(e.File.Path))
+	e.fprintChildren(w, "")
+}
+
+func (e *FileEntry) fprintChildren(w io.Writer, indent string) {
+	for i, entry := range e.Children {
+		branch, nextIndent := "├── ", "│   "
+		if i == len(e.Children)-1 {
+			branch, nextIndent = "└── ", "    "
+		}
+		fmt.Fprintln(w, indent+branch+entry.describe(entry.File.Name))
+		entry.fprintChildren(w, indent+nextIndent)
+	}
+}
+
+func (e *FileEntry) describe(name string) string {
+	f := &e.File
+	if f.IsFolder() {
+		if !strings.HasSuffix(name, "/") {
+			name += "/"
+		}
+	} else {
+		name += "  " + FormatSize(f.Size())
+	}
+	if modified := f.Modified(); !modified.IsZero() {
+		name += "  " + modified.Format("2006-01-02 15:04")
 	}
+	if f.IsLocked() {
+		name += "  [locked]"
+	}
+	if f.IsHidden() {
+		name += "  [hidden]"
+	}
+	return name
+}
+
+// FileNode is the file entry for the machine-readable output.
+type FileNode struct {
+	Name     string     `json:"name" yaml:"name"`
+	Path     string     `json:"path" yaml:"path"`
+	Folder   bool       `json:"folder" yaml:"folder"`
+	Size     int64      `json:"size" yaml:"size"`
+	Created  *time.Time `json:"created,omitempty" yaml:"created,omitempty"`
+	Modified *time.Time `json:"modified,omitempty" yaml:"modified,omitempty"`
+	Locked   bool       `json:"locked" yaml:"locked"`
+	Hidden   bool       `json:"hidden" yaml:"hidden"`
+	Children []FileNode `json:"children,omitempty" yaml:"children,omitempty"`
 }
 
-// ImportParameters is the parameters of import API.
-type ImportParameters struct {
-	OverwriteFile           bool
-	OverwriteACLPermissions bool
-	ApplyACLPermissions     bool
-	RetainOwnership         bool
-	Charset                 string
-	LogLevel                string
-	FileNameOverride        string
+// Node converts the file entry to FileNode recursively.
+func (e *FileEntry) Node() FileNode {
+	node := FileNode{
+		Name:   e.File.Name,
+		Path:   e.File.Path,
+		Folder: e.File.IsFolder(),
+		Size:   e.File.Size(),
+		Locked: e.File.IsLocked(),
+		Hidden: e.File.IsHidden(),
+	}
+	if created := e.File.Created(); !created.IsZero() {
+		node.Created = &created
+	}
+	if modified := e.File.Modified(); !modified.IsZero() {
+		node.Modified = &modified
+	}
+	for i := range e.Children {
+		node.Children = append(node.Children, e.Children[i].Node())
+	}
+	return node
 }
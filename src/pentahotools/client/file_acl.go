@@ -0,0 +1,256 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// SetACL sets the access control list of file.
+func (c *Client) SetACL(path string, acl *ACL) error {
+	c.Logger.Debug("SetACL", zap.String("path", path))
+	resp, err := c.client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(acl).
+		Put(fmt.Sprintf("api/repo/files/%s/acl", strings.Replace(path, "/", ":", -1)))
+
+	switch resp.StatusCode() {
+	case 200:
+		return nil
+	case 403:
+		return errors.New("Failed to save acls due to missing or incorrect properties")
+	case 400:
+		return errors.New("Failed to save acls due to malformed xml")
+	case 500:
+		return errors.New("Failed to save acls due to another error")
+	default:
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("Unknown error. statusCode=%d", resp.StatusCode())
+	}
+}
+
+// The recipient types of Ac.
+const (
+	RecipientTypeUser = "0"
+	RecipientTypeRole = "1"
+)
+
+// The permissions of Ac.
+const (
+	PermissionRead   = "0"
+	PermissionWrite  = "1"
+	PermissionDelete = "2"
+	PermissionManage = "3"
+	PermissionAll    = "4"
+)
+
+// permissionNames are the names of the permissions in the command line.
+var permissionNames = map[string]string{
+	PermissionRead:   "read",
+	PermissionWrite:  "write",
+	PermissionDelete: "delete",
+	PermissionManage: "manage",
+	PermissionAll:    "all",
+}
+
+// ParsePermissions parses the comma separated permission names. (e.g., "read,write")
+func ParsePermissions(s string) ([]string, error) {
+	var permissions []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		found := false
+		for permission, n := range permissionNames {
+			if n == name {
+				permissions = append(permissions, permission)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unsupported permission: %s (read/write/delete/manage/all)", name)
+		}
+	}
+	return normalizePermissions(permissions), nil
+}
+
+// PermissionNames gets the names of the permissions.
+func PermissionNames(permissions []string) []string {
+	var names []string
+	for _, p := range normalizePermissions(permissions) {
+		if name, ok := permissionNames[p]; ok {
+			names = append(names, name)
+		} else {
+			names = append(names, p)
+		}
+	}
+	return names
+}
+
+// normalizePermissions sorts the permissions and removes the duplicates.
+// The permissions are replaced with 'all' if all of them are included.
+func normalizePermissions(permissions []string) []string {
+	set := map[string]bool{}
+	for _, p := range permissions {
+		set[p] = true
+	}
+	if set[PermissionAll] || (set[PermissionRead] && set[PermissionWrite] && set[PermissionDelete] && set[PermissionManage]) {
+		return []string{PermissionAll}
+	}
+	normalized := []string{}
+	for p := range set {
+		normalized = append(normalized, p)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// expandPermissions expands 'all' to the each permissions.
+func expandPermissions(permissions []string) []string {
+	for _, p := range permissions {
+		if p == PermissionAll {
+			return []string{PermissionRead, PermissionWrite, PermissionDelete, PermissionManage}
+		}
+	}
+	return permissions
+}
+
+// RecipientTypeName gets the name of the recipient type.
+func RecipientTypeName(recipientType string) string {
+	switch recipientType {
+	case RecipientTypeUser:
+		return "user"
+	case RecipientTypeRole:
+		return "role"
+	}
+	return recipientType
+}
+
+// IsInheriting checks if the entries are inherited from the parent folder.
+func (a *ACL) IsInheriting() bool {
+	return a.EntriesInheriting == "true"
+}
+
+// SetInheriting changes whether the entries are inherited from the parent folder.
+// The current effective entries are kept as the explicit entries when the inheritance is stopped, as the PUC does.
+func (a *ACL) SetInheriting(inheriting bool) {
+	a.EntriesInheriting = fmt.Sprint(inheriting)
+	if inheriting {
+		a.Aces = nil
+	}
+}
+
+// Find finds the entry of the recipient.
+func (a *ACL) Find(recipient string, recipientType string) *Ac {
+	for i := range a.Aces {
+		if a.Aces[i].Recipient == recipient && a.Aces[i].RecipientType == recipientType {
+			return &a.Aces[i]
+		}
+	}
+	return nil
+}
+
+// Grant adds the permissions to the recipient.
+// The inheritance is stopped to change the entries.
+func (a *ACL) Grant(recipient string, recipientType string, permissions []string) {
+	a.SetInheriting(false)
+	ac := a.Find(recipient, recipientType)
+	if ac == nil {
+		a.Aces = append(a.Aces, Ac{"true", normalizePermissions(permissions), recipient, recipientType})
+		return
+	}
+	ac.Permissions = normalizePermissions(append(ac.Permissions, permissions...))
+}
+
+// Revoke removes the permissions from the recipient.
+// The entry is removed if no permissions are specified or no permissions remain.
+// The inheritance is stopped to change the entries.
+func (a *ACL) Revoke(recipient string, recipientType string, permissions []string) {
+	a.SetInheriting(false)
+	ac := a.Find(recipient, recipientType)
+	if ac == nil {
+		return
+	}
+	var remaining []string
+	if len(permissions) > 0 {
+		revoking := map[string]bool{}
+		for _, p := range expandPermissions(permissions) {
+			revoking[p] = true
+		}
+		for _, p := range expandPermissions(ac.Permissions) {
+			if !revoking[p] {
+				remaining = append(remaining, p)
+			}
+		}
+	}
+	if len(remaining) > 0 {
+		ac.Permissions = normalizePermissions(remaining)
+		return
+	}
+	var aces AcList
+	for _, e := range a.Aces {
+		if e.Recipient != recipient || e.RecipientType != recipientType {
+			aces = append(aces, e)
+		}
+	}
+	a.Aces = aces
+}
+
+// Set replaces the permissions of the recipient.
+// The inheritance is stopped to change the entries.
+func (a *ACL) Set(recipient string, recipientType string, permissions []string) {
+	a.Revoke(recipient, recipientType, nil)
+	if len(permissions) > 0 {
+		a.Grant(recipient, recipientType, permissions)
+	}
+}
+
+// AcList is a list of Ac.
+// The entry is serialized as an object instead of an array if there is only one entry.
+type AcList []Ac
+
+// UnmarshalJSON unmarshals both of the object and the array.
+func (l *AcList) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '{' {
+		var ac Ac
+		if err := json.Unmarshal(data, &ac); err != nil {
+			return err
+		}
+		*l = AcList{ac}
+		return nil
+	}
+	var aces []Ac
+	if err := json.Unmarshal(data, &aces); err != nil {
+		return err
+	}
+	*l = aces
+	return nil
+}
+
+// jsonStrings is a string list which can be unmarshaled from both of JSON string and array.
+type jsonStrings []string
+
+func (s *jsonStrings) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var v string
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		*s = jsonStrings{v}
+		return nil
+	}
+	var values []string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*s = values
+	return nil
+}
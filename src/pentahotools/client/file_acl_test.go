@@ -0,0 +1,55 @@
+package client
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalACL(t *testing.T) {
+	var acl ACL
+	data := `{"aces":{"modifiable":"true","permissions":"4","recipient":"admin","recipientType":"0"},"entriesInheriting":"false","id":"1","owner":"admin","ownerType":"0"}`
+	if err := json.Unmarshal([]byte(data), &acl); err != nil {
+		t.Fatal(err)
+	}
+	if len(acl.Aces) != 1 || !reflect.DeepEqual([]string(acl.Aces[0].Permissions), []string{"4"}) {
+		t.Errorf("unexpected aces: %v", acl.Aces)
+	}
+	data = `{"aces":[{"permissions":["0","1"],"recipient":"Sales","recipientType":"1"},{"permissions":"0","recipient":"Authenticated","recipientType":"1"}],"entriesInheriting":"true"}`
+	if err := json.Unmarshal([]byte(data), &acl); err != nil {
+		t.Fatal(err)
+	}
+	if len(acl.Aces) != 2 || !reflect.DeepEqual([]string(acl.Aces[0].Permissions), []string{"0", "1"}) || !acl.IsInheriting() {
+		t.Errorf("unexpected acl: %v", acl)
+	}
+}
+
+func TestACLGrantRevoke(t *testing.T) {
+	acl := &ACL{
+		EntriesInheriting: "true",
+		Aces:              AcList{{"true", jsonStrings{PermissionRead}, "Sales", RecipientTypeRole}},
+	}
+	acl.Grant("Sales", RecipientTypeRole, []string{PermissionWrite})
+	acl.Grant("joe", RecipientTypeUser, []string{PermissionRead, PermissionWrite, PermissionDelete, PermissionManage})
+	if acl.IsInheriting() {
+		t.Error("the inheritance must be stopped")
+	}
+	if names := PermissionNames(acl.Find("Sales", RecipientTypeRole).Permissions); !reflect.DeepEqual(names, []string{"read", "write"}) {
+		t.Errorf("unexpected permissions: %v", names)
+	}
+	if names := PermissionNames(acl.Find("joe", RecipientTypeUser).Permissions); !reflect.DeepEqual(names, []string{"all"}) {
+		t.Errorf("unexpected permissions: %v", names)
+	}
+	acl.Revoke("joe", RecipientTypeUser, []string{PermissionManage})
+	if names := PermissionNames(acl.Find("joe", RecipientTypeUser).Permissions); !reflect.DeepEqual(names, []string{"read", "write", "delete"}) {
+		t.Errorf("unexpected permissions: %v", names)
+	}
+	acl.Revoke("Sales", RecipientTypeRole, nil)
+	if acl.Find("Sales", RecipientTypeRole) != nil || len(acl.Aces) != 1 {
+		t.Errorf("unexpected aces: %v", acl.Aces)
+	}
+	acl.Set("joe", RecipientTypeUser, []string{PermissionRead})
+	if names := PermissionNames(acl.Find("joe", RecipientTypeUser).Permissions); !reflect.DeepEqual(names, []string{"read"}) {
+		t.Errorf("unexpected permissions: %v", names)
+	}
+}
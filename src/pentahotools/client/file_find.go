@@ -0,0 +1,131 @@
+package client
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileFilter is the condition to find files in the repository tree.
+// The zero values are ignored.
+type FileFilter struct {
+	// Name is the glob pattern of the file name. (e.g., "*.ktr")
+	Name string
+	// Type is "file" or "folder".
+	Type          string
+	LargerThan    int64
+	SmallerThan   int64
+	CreatedBefore time.Time
+	CreatedAfter  time.Time
+	Locked        bool
+	Hidden        bool
+}
+
+// Validate validates the filter.
+func (f *FileFilter) Validate() error {
+	if _, err := path.Match(f.Name, ""); err != nil {
+		return fmt.Errorf("invalid name pattern: %s", f.Name)
+	}
+	switch f.Type {
+	case "", "file", "folder":
+		return nil
+	}
+	return fmt.Errorf("invalid type: %s (file/folder)", f.Type)
+}
+
+// Match checks if the file matches the filter.
+func (f *FileFilter) Match(file *FileInfo) bool {
+	if f.Name != "" {
+		if matched, _ := path.Match(f.Name, file.Name); !matched {
+			return false
+		}
+	}
+	switch f.Type {
+	case "file":
+		if file.IsFolder() {
+			return false
+		}
+	case "folder":
+		if !file.IsFolder() {
+			return false
+		}
+	}
+	if f.LargerThan > 0 && file.Size() <= f.LargerThan {
+		return false
+	}
+	if f.SmallerThan > 0 && file.Size() >= f.SmallerThan {
+		return false
+	}
+	created := file.Created()
+	if !f.CreatedBefore.IsZero() && (created.IsZero() || !created.Before(f.CreatedBefore)) {
+		return false
+	}
+	if !f.CreatedAfter.IsZero() && !created.After(f.CreatedAfter) {
+		return false
+	}
+	if f.Locked && !file.IsLocked() {
+		return false
+	}
+	if f.Hidden && !file.IsHidden() {
+		return false
+	}
+	return true
+}
+
+// Find finds the descendants of the entry which match the filter.
+func (e *FileEntry) Find(filter *FileFilter) []FileInfo {
+	var files []FileInfo
+	e.Walk(func(entry *FileEntry) error {
+		if entry != e && filter.Match(&entry.File) {
+			files = append(files, entry.File)
+		}
+		return nil
+	})
+	return files
+}
+
+// sizeUnits are the units of ParseSize.
+var sizeUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"TB", 1 << 40},
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"T", 1 << 40},
+	{"G", 1 << 30},
+	{"M", 1 << 20},
+	{"K", 1 << 10},
+	{"B", 1},
+}
+
+// ParseSize parses the size with the binary unit. (e.g., "1MB", "500K", "1024")
+func ParseSize(s string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	unit := int64(1)
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(value, u.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, u.suffix))
+			unit = u.size
+			break
+		}
+	}
+	size, err := strconv.ParseFloat(value, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid size: %s", s)
+	}
+	return int64(size * float64(unit)), nil
+}
+
+// FormatSize formats the size with the binary unit. (e.g., "1.5MB")
+func FormatSize(size int64) string {
+	for _, u := range sizeUnits[:4] {
+		if size >= u.size {
+			return strconv.FormatFloat(float64(size)/float64(u.size), 'f', 1, 64) + u.suffix
+		}
+	}
+	return strconv.FormatInt(size, 10) + "B"
+}
@@ -0,0 +1,58 @@
+package client
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParseSize(t *testing.T) {
+	for s, expected := range map[string]int64{
+		"1024":  1024,
+		"1MB":   1 << 20,
+		"1.5k":  1536,
+		"2 GB":  2 << 30,
+		"100B":  100,
+		"0.5TB": 1 << 39,
+	} {
+		actual, err := ParseSize(s)
+		if err != nil {
+			t.Errorf("%s: %s", s, err)
+		} else if actual != expected {
+			t.Errorf("%s: expected %d but %d", s, expected, actual)
+		}
+	}
+	if _, err := ParseSize("1XB"); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestFileFilterMatch(t *testing.T) {
+	created := time.Date(2017, 10, 1, 0, 0, 0, 0, time.Local)
+	file := &FileInfo{
+		Name:        "a.ktr",
+		FileSize:    "2048",
+		CreatedDate: strconv.FormatInt(created.UnixNano()/int64(time.Millisecond), 10),
+		Folder:      "false",
+		Locked:      "true",
+	}
+	for i, c := range []struct {
+		filter   FileFilter
+		expected bool
+	}{
+		{FileFilter{}, true},
+		{FileFilter{Name: "*.ktr", Type: "file"}, true},
+		{FileFilter{Name: "*.kjb"}, false},
+		{FileFilter{Type: "folder"}, false},
+		{FileFilter{LargerThan: 1024}, true},
+		{FileFilter{LargerThan: 2048}, false},
+		{FileFilter{CreatedBefore: created.AddDate(0, 0, 1)}, true},
+		{FileFilter{CreatedBefore: created}, false},
+		{FileFilter{CreatedAfter: created.AddDate(0, 0, -1), Locked: true}, true},
+		{FileFilter{Hidden: true}, false},
+	} {
+		if actual := c.filter.Match(file); actual != c.expected {
+			t.Errorf("%d: expected %v but %v", i, c.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package client
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// ImportLogLevels are the levels of the import log.
+var ImportLogLevels = []string{"TRACE", "DEBUG", "INFO", "WARN", "ERROR", "FATAL"}
+
+// ImportParameters is the parameters of import API.
+type ImportParameters struct {
+	OverwriteFile           bool
+	OverwriteACLPermissions bool
+	ApplyACLPermissions     bool
+	RetainOwnership         bool
+	Charset                 string
+	// LogLevel is the level of the import log. (TRACE/DEBUG/INFO/WARN/ERROR/FATAL)
+	// The imported files are reported only with INFO or lower levels.
+	LogLevel         string
+	FileNameOverride string
+}
+
+// The statuses of ImportedFile.
+const (
+	ImportStatusImported = "imported"
+	ImportStatusSkipped  = "skipped"
+	ImportStatusFailed   = "failed"
+)
+
+// ImportResult represents the result of ImportFile parsed from the import log.
+type ImportResult struct {
+	// Log is the import log returned by the server.
+	Log   string
+	Files []ImportedFile
+}
+
+// ImportedFile represents the result of a file in the import log.
+// Path is empty for the errors not related to a file.
+type ImportedFile struct {
+	Path    string
+	Status  string
+	Message string
+}
+
+// ImportFile imports a file to the directory in the repository.
+// The result is returned even if some files are failed to import.
+func (c *Client) ImportFile(file string, importDir string, params *ImportParameters) (*ImportResult, error) {
+	c.Logger.Debug("ImportFile", zap.String("file", file), zap.String("importDir", importDir), zap.String("params", fmt.Sprint(params)))
+	resp, err := c.client.R().
+		SetFiles(map[string]string{
+			"fileUpload": file,
+		}).
+		SetMultiValueFormData(url.Values{
+			"overwriteFile":           []string{strconv.FormatBool(params.OverwriteFile)},
+			"logLevel":                []string{params.LogLevel},
+			"retainOwnership":         []string{strconv.FormatBool(params.RetainOwnership)},
+			"fileNameOverride":        []string{params.FileNameOverride},
+			"importDir":               []string{importDir},
+			"charSet":                 []string{params.Charset},
+			"applyAclPermissions":     []string{strconv.FormatBool(params.ApplyACLPermissions)},
+			"overwriteAclPermissions": []string{strconv.FormatBool(params.OverwriteACLPermissions)},
+		}).
+		Post("api/repo/files/import")
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode() {
+	case 200:
+		return ParseImportLog(resp.String()), nil
+	case 403:
+		return nil, errors.New("Failure to import the file due to permissions")
+	case 500:
+		return nil, errors.New("server error")
+	default:
+		return nil, fmt.Errorf("Unknown error. statusCode=%d", resp.StatusCode())
+	}
+}
+
+var (
+	importLogRowPattern  = regexp.MustCompile(`(?is)<tr[^>]*>(.*?)</tr>`)
+	importLogCellPattern = regexp.MustCompile(`(?is)<t([hd])[^>]*>(.*?)</t[hd]>`)
+	importLogTagPattern  = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// ParseImportLog parses the HTML import log into the results of the files.
+// The log is a table of the time, the level, the file and the message.
+func ParseImportLog(log string) *ImportResult {
+	result := &ImportResult{Log: log}
+	levelColumn, fileColumn, messageColumn := 1, 2, 3
+	indexes := map[string]int{}
+	for _, row := range importLogRowPattern.FindAllStringSubmatch(log, -1) {
+		var cells []string
+		header := false
+		for _, cell := range importLogCellPattern.FindAllStringSubmatch(row[1], -1) {
+			header = strings.ToLower(cell[1]) == "h"
+			cells = append(cells, strings.TrimSpace(html.UnescapeString(importLogTagPattern.ReplaceAllString(cell[2], ""))))
+		}
+		if header {
+			for i, name := range cells {
+				switch strings.ToLower(name) {
+				case "level":
+					levelColumn = i
+				case "file":
+					fileColumn = i
+				case "message":
+					messageColumn = i
+				}
+			}
+			continue
+		}
+		if len(cells) <= levelColumn || len(cells) <= messageColumn {
+			continue
+		}
+		var path string
+		if fileColumn < len(cells) && fileColumn != messageColumn {
+			path = cells[fileColumn]
+		}
+		result.add(path, cells[levelColumn], cells[messageColumn], indexes)
+	}
+	return result
+}
+
+// add adds the log entry to the result.
+// The status of the file is only changed to the more severe one.
+func (r *ImportResult) add(path string, level string, message string, indexes map[string]int) {
+	status := ImportStatusImported
+	lowerMessage := strings.ToLower(message)
+	switch {
+	case level == "ERROR" || level == "FATAL":
+		status = ImportStatusFailed
+	case strings.Contains(lowerMessage, "skip") || strings.Contains(lowerMessage, "already exists"):
+		status = ImportStatusSkipped
+	case path == "":
+		return
+	}
+	if path == "" {
+		r.Files = append(r.Files, ImportedFile{Status: status, Message: message})
+		return
+	}
+	i, exists := indexes[path]
+	if !exists {
+		indexes[path] = len(r.Files)
+		r.Files = append(r.Files, ImportedFile{Path: path, Status: ImportStatusImported})
+		i = indexes[path]
+	}
+	f := &r.Files[i]
+	if importStatusSeverity(status) > importStatusSeverity(f.Status) {
+		f.Status = status
+		f.Message = message
+	}
+}
+
+func importStatusSeverity(status string) int {
+	switch status {
+	case ImportStatusFailed:
+		return 2
+	case ImportStatusSkipped:
+		return 1
+	}
+	return 0
+}
+
+// Filter gets the files with the status.
+func (r *ImportResult) Filter(status string) []ImportedFile {
+	var files []ImportedFile
+	for _, f := range r.Files {
+		if f.Status == status {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// HasFailures checks if some files are failed to import.
+func (r *ImportResult) HasFailures() bool {
+	return len(r.Filter(ImportStatusFailed)) > 0
+}
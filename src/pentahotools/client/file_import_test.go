@@ -0,0 +1,37 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testImportLog = `<html><body>
+<table cellspacing="0" cellpadding="4" border="1">
+<tr><th>Time</th><th>Level</th><th>File</th><th>Message</th></tr>
+<tr><td>0</td><td title="Level">INFO</td><td>/public/sales</td><td title="Message">Start File Import</td></tr>
+<tr><td>1</td><td title="Level">INFO</td><td>/public/sales/a.ktr</td><td title="Message">Start File Import</td></tr>
+<tr><td>2</td><td title="Level">INFO</td><td>/public/sales/b.ktr</td><td title="Message">File /public/sales/b.ktr already exists, skipping</td></tr>
+<tr><td>3</td><td title="Level">ERROR</td><td>/public/sales/c&amp;d.prpt</td><td title="Message"><b>Access denied</b></td></tr>
+<tr><td>4</td><td title="Level">INFO</td><td>/public/sales/c&amp;d.prpt</td><td title="Message">End File Import</td></tr>
+<tr><td>5</td><td title="Level">ERROR</td><td></td><td title="Message">Import aborted</td></tr>
+</table></body></html>`
+
+func TestParseImportLog(t *testing.T) {
+	result := ParseImportLog(testImportLog)
+	expected := []ImportedFile{
+		{"/public/sales", ImportStatusImported, ""},
+		{"/public/sales/a.ktr", ImportStatusImported, ""},
+		{"/public/sales/b.ktr", ImportStatusSkipped, "File /public/sales/b.ktr already exists, skipping"},
+		{"/public/sales/c&d.prpt", ImportStatusFailed, "Access denied"},
+		{"", ImportStatusFailed, "Import aborted"},
+	}
+	if !reflect.DeepEqual(result.Files, expected) {
+		t.Errorf("expected %v, got %v", expected, result.Files)
+	}
+	if !result.HasFailures() || len(result.Filter(ImportStatusImported)) != 2 {
+		t.Errorf("unexpected result: %v", result.Files)
+	}
+	if result := ParseImportLog(""); result.HasFailures() || len(result.Files) != 0 {
+		t.Errorf("expected empty result, got %v", result.Files)
+	}
+}
@@ -0,0 +1,142 @@
+package client
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// Modes of CopyFiles API, which are MODE_OVERWRITE, MODE_RENAME and MODE_NO_OVERWRITE of FileService.
+// MODE_RENAME is not used because it creates the renamed copies silently on the name clash.
+const (
+	copyModeOverwrite   = "1"
+	copyModeRename      = "2"
+	copyModeNoOverwrite = "3"
+)
+
+// MoveFiles moves the files into the destination folder.
+func (c *Client) MoveFiles(destinationFolder string, repositoryPaths ...string) error {
+	c.Logger.Debug("MoveFiles", zap.String("destinationFolder", destinationFolder), zap.Strings("repositoryPaths", repositoryPaths))
+	ids, err := c.getFileIDs(repositoryPaths...)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.R().
+		SetBody(strings.Join(ids, ",")).
+		Put(fmt.Sprintf("api/repo/files/%s/move", strings.Replace(destinationFolder, "/", ":", -1)))
+	switch resp.StatusCode() {
+	case 200:
+		return nil
+	case 403:
+		return errors.New("Failure to move the files due to permissions")
+	case 404:
+		return errors.New("destination folder not found: " + destinationFolder)
+	case 500:
+		return errors.New("Failure to move the files, the destination may already have the file with the same name")
+	default:
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("Unknown error. statusCode=%d", resp.StatusCode())
+	}
+}
+
+// CopyFiles copies the files into the destination folder.
+// The folders are copied with their descendants.
+func (c *Client) CopyFiles(destinationFolder string, overwrite bool, repositoryPaths ...string) error {
+	c.Logger.Debug("CopyFiles", zap.String("destinationFolder", destinationFolder), zap.Bool("overwrite", overwrite), zap.Strings("repositoryPaths", repositoryPaths))
+	ids, err := c.getFileIDs(repositoryPaths...)
+	if err != nil {
+		return err
+	}
+	mode := copyModeNoOverwrite
+	if overwrite {
+		mode = copyModeOverwrite
+	}
+	resp, err := c.client.R().
+		SetQueryParam("mode", mode).
+		SetBody(strings.Join(ids, ",")).
+		Put(fmt.Sprintf("api/repo/files/%s/children", strings.Replace(destinationFolder, "/", ":", -1)))
+	switch resp.StatusCode() {
+	case 200:
+		return nil
+	case 403:
+		return errors.New("Failure to copy the files due to permissions")
+	case 500:
+		return errors.New("server error")
+	default:
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("Unknown error. statusCode=%d", resp.StatusCode())
+	}
+}
+
+// RenameFile renames the file or folder.
+func (c *Client) RenameFile(repositoryPath string, newName string) error {
+	c.Logger.Debug("RenameFile", zap.String("repositoryPath", repositoryPath), zap.String("newName", newName))
+	if strings.Contains(newName, "/") {
+		return errors.New("the new name must not contain '/': " + newName)
+	}
+	resp, err := c.client.R().
+		SetQueryParam("newName", newName).
+		Put(fmt.Sprintf("api/repo/files/%s/rename", strings.Replace(repositoryPath, "/", ":", -1)))
+	switch resp.StatusCode() {
+	case 200:
+		return nil
+	case 403:
+		return errors.New("Failure to rename the file due to permissions")
+	case 404:
+		return errors.New("file not found: " + repositoryPath)
+	case 500:
+		return errors.New("Failure to rename the file, the file with the same name may already exist")
+	default:
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("Unknown error. statusCode=%d", resp.StatusCode())
+	}
+}
+
+// GetFileInfo gets the information of the file.
+// It returns nil if the file does not exist.
+func (c *Client) GetFileInfo(repositoryPath string) (*FileInfo, error) {
+	repositoryPath = path.Clean("/" + repositoryPath)
+	if repositoryPath == "/" {
+		root, err := c.Tree("/", 0, true)
+		if err != nil {
+			return nil, err
+		}
+		return &root.File, nil
+	}
+	parent, err := c.Tree(path.Dir(repositoryPath), 1, true)
+	if err == ErrNotFound {
+		// the parent folder does not exist.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range parent.Children {
+		if child.File.Path == repositoryPath {
+			return &child.File, nil
+		}
+	}
+	return nil, nil
+}
+
+// getFileIDs gets the IDs of the files.
+func (c *Client) getFileIDs(repositoryPaths ...string) ([]string, error) {
+	ids := make([]string, len(repositoryPaths))
+	for i, path := range repositoryPaths {
+		acl, err := c.GetACL(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get the ID of the file:"+path)
+		}
+		ids[i] = acl.ID
+	}
+	return ids, nil
+}
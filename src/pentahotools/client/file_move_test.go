@@ -0,0 +1,71 @@
+package client
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestGetFileInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, _, _ := r.BasicAuth(); user != "admin" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/repo/files/:public/tree":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"file":{"path":"/public","name":"public","folder":"true"},"children":[{"file":{"path":"/public/a.ktr","name":"a.ktr","folder":"false"}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, "admin", "password")
+	if info, err := c.GetFileInfo("/public/a.ktr"); err != nil || info == nil || info.Name != "a.ktr" {
+		t.Errorf("unexpected result: %v, %v", info, err)
+	}
+	if info, err := c.GetFileInfo("/public/b.ktr"); err != nil || info != nil {
+		t.Errorf("expected nil for the missing file, got %v, %v", info, err)
+	}
+	if info, err := c.GetFileInfo("/missing/a.ktr"); err != nil || info != nil {
+		t.Errorf("expected nil for the missing folder, got %v, %v", info, err)
+	}
+	c = NewClient(server.URL, "guest", "password")
+	if info, err := c.GetFileInfo("/public/a.ktr"); err == nil {
+		t.Errorf("expected error for the unauthorized request, got %v", info)
+	}
+}
+
+func TestCopyFilesMode(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/repo/files/:public:a.ktr/acl":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id":"a-id","owner":"admin"}`))
+		case r.Method == "PUT" && r.URL.Path == "/api/repo/files/:public:dest/children":
+			body, _ := ioutil.ReadAll(r.Body)
+			requests = append(requests, r.URL.Query().Get("mode")+" "+string(body))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, "admin", "password")
+	if err := c.CopyFiles("/public/dest", false, "/public/a.ktr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.CopyFiles("/public/dest", true, "/public/a.ktr"); err != nil {
+		t.Fatal(err)
+	}
+	// the modes of FileService: 1=overwrite, 2=rename, 3=no overwrite
+	expected := []string{"3 a-id", "1 a-id"}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("expected %v but %v", expected, requests)
+	}
+}
@@ -0,0 +1,31 @@
+package client
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFileEntryFprint(t *testing.T) {
+	root := &FileEntry{
+		File: FileInfo{Name: "public", Path: "/public", Folder: "true"},
+		Children: []FileEntry{
+			{
+				File: FileInfo{Name: "project", Path: "/public/project", Folder: "true"},
+				Children: []FileEntry{
+					{File: FileInfo{Name: "a.ktr", Path: "/public/project/a.ktr", FileSize: "1536", Locked: "true"}},
+				},
+			},
+			{File: FileInfo{Name: "b.kjb", Path: "/public/b.kjb", FileSize: "10", Hidden: "true"}},
+		},
+	}
+	var buf bytes.Buffer
+	root.Fprint(&buf)
+	expected := `/public/
+├── project/
+│   └── a.ktr  1.5KB  [locked]
+└── b.kjb  10B  [hidden]
+`
+	if buf.String() != expected {
+		t.Errorf("expected\n%s\nbut\n%s", expected, buf.String())
+	}
+}
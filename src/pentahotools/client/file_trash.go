@@ -0,0 +1,68 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// ListDeletedFiles lists the files in the trash.
+func (c *Client) ListDeletedFiles() ([]FileInfo, error) {
+	c.Logger.Debug("ListDeletedFiles")
+	var files fileInfoList
+	resp, err := c.client.R().
+		SetHeader("Accept", "application/json").
+		SetResult(&files).
+		Get("api/repo/files/deleted")
+	switch resp.StatusCode() {
+	case 200:
+		return files.list()
+	case 500:
+		return nil, errors.New("server error")
+	default:
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("Unknown error. statusCode=%d", resp.StatusCode())
+	}
+}
+
+// RestoreFiles restores the files in the trash to the original folders.
+func (c *Client) RestoreFiles(ids ...string) error {
+	c.Logger.Debug("RestoreFiles", zap.Strings("ids", ids))
+	resp, err := c.client.R().
+		SetBody(strings.Join(ids, ",")).
+		Put("api/repo/files/restore")
+	switch resp.StatusCode() {
+	case 200:
+		return nil
+	case 403:
+		return errors.New("Failure to restore the files due to permissions")
+	case 500:
+		return errors.New("Failure to restore the files, the original folder may already have the file with the same name")
+	default:
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("Unknown error. statusCode=%d", resp.StatusCode())
+	}
+}
+
+// DeleteDeletedFiles deletes the files in the trash permanently.
+func (c *Client) DeleteDeletedFiles(ids ...string) error {
+	c.Logger.Debug("DeleteDeletedFiles", zap.Strings("ids", ids))
+	return c.deleteFileIDs(true, ids...)
+}
+
+// Deleted gets the date when the file is moved to the trash.
+func (f *FileInfo) Deleted() time.Time {
+	return parseFileDate(f.DeletedDate)
+}
+
+// OriginalPath gets the path of the file before moved to the trash.
+func (f *FileInfo) OriginalPath() string {
+	return strings.TrimSuffix(f.OriginalParentFolderPath, "/") + "/" + f.Name
+}
@@ -0,0 +1,143 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// VersionSummary represents a version of the file.
+type VersionSummary struct {
+	ID              string `json:"id"`
+	Author          string `json:"author"`
+	Date            string `json:"date"`
+	Message         string `json:"message"`
+	VersionedFileID string `json:"versionedFileId"`
+	ACLOnly         string `json:"aclOnly"`
+}
+
+// Created gets the date when the version is created.
+func (v *VersionSummary) Created() time.Time {
+	if t := parseFileDate(v.Date); !t.IsZero() {
+		return t
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.000-07:00", "2006-01-02T15:04:05.000Z0700"} {
+		if t, err := time.Parse(layout, v.Date); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// versionSummaryList is the response of the versions API.
+// The version is serialized as an object instead of an array if there is only one version.
+type versionSummaryList struct {
+	VersionSummary json.RawMessage `json:"versionSummary"`
+}
+
+func (l *versionSummaryList) list() ([]VersionSummary, error) {
+	if len(l.VersionSummary) == 0 {
+		return nil, nil
+	}
+	if l.VersionSummary[0] == '{' {
+		var version VersionSummary
+		if err := json.Unmarshal(l.VersionSummary, &version); err != nil {
+			return nil, err
+		}
+		return []VersionSummary{version}, nil
+	}
+	var versions []VersionSummary
+	if err := json.Unmarshal(l.VersionSummary, &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// ListVersions lists the versions of the file.
+func (c *Client) ListVersions(repositoryPath string) ([]VersionSummary, error) {
+	c.Logger.Debug("ListVersions", zap.String("repositoryPath", repositoryPath))
+	var versions versionSummaryList
+	resp, err := c.client.R().
+		SetHeader("Accept", "application/json").
+		SetResult(&versions).
+		Get(fmt.Sprintf("api/repo/files/%s/versions", strings.Replace(repositoryPath, "/", ":", -1)))
+	switch resp.StatusCode() {
+	case 200:
+		return versions.list()
+	case 404:
+		return nil, errors.New("file not found: " + repositoryPath)
+	case 500:
+		return nil, errors.New("server error")
+	default:
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("Unknown error. statusCode=%d", resp.StatusCode())
+	}
+}
+
+// checkVersion checks if the version exists, because the content API returns the latest version for the unknown version.
+// It returns the versions of the file.
+func (c *Client) checkVersion(repositoryPath string, versionID string) ([]VersionSummary, error) {
+	versions, err := c.ListVersions(repositoryPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range versions {
+		if v.ID == versionID {
+			return versions, nil
+		}
+	}
+	return nil, errors.New("version not found: " + repositoryPath + " " + versionID)
+}
+
+// GetFileVersion gets the version of the file from the repository.
+func (c *Client) GetFileVersion(repositoryPath string, versionID string, destination string) error {
+	if _, err := c.getFileVersion(repositoryPath, versionID, destination); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetFileVersionContent gets the content of the version of the file.
+func (c *Client) GetFileVersionContent(repositoryPath string, versionID string) ([]byte, error) {
+	return c.getFileVersion(repositoryPath, versionID, "")
+}
+
+// RestoreFileVersion restores the file to the version.
+// The restored content is saved as a new version, and it is an error if no version is added.
+func (c *Client) RestoreFileVersion(repositoryPath string, versionID string) error {
+	c.Logger.Debug("RestoreFileVersion", zap.String("repositoryPath", repositoryPath), zap.String("versionID", versionID))
+	versions, err := c.checkVersion(repositoryPath, versionID)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.R().
+		Put(fmt.Sprintf("api/repo/files/%s/versions/%s", strings.Replace(repositoryPath, "/", ":", -1), versionID))
+	switch resp.StatusCode() {
+	case 200:
+		restored, err := c.ListVersions(repositoryPath)
+		if err != nil {
+			return err
+		}
+		if len(restored) <= len(versions) {
+			return errors.New("the version is not restored: " + repositoryPath + " " + versionID)
+		}
+		return nil
+	case 403:
+		return errors.New("Failure to restore the version due to permissions")
+	case 404:
+		return errors.New("file or version not found: " + repositoryPath + " " + versionID)
+	case 500:
+		return errors.New("server error")
+	default:
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("Unknown error. statusCode=%d", resp.StatusCode())
+	}
+}
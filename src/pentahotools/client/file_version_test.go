@@ -0,0 +1,159 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVersionSummaryList(t *testing.T) {
+	for _, data := range []string{
+		`{"versionSummary":{"id":"1.0","author":"admin","date":"1500000000000"}}`,
+		`{"versionSummary":[{"id":"1.0","author":"admin","date":"1500000000000"},{"id":"1.1","author":"suzy","date":"2017-07-14T11:40:00.000+09:00"}]}`,
+	} {
+		var l versionSummaryList
+		if err := json.Unmarshal([]byte(data), &l); err != nil {
+			t.Fatal(err)
+		}
+		versions, err := l.list()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(versions) == 0 || versions[0].ID != "1.0" || versions[0].Author != "admin" {
+			t.Errorf("unexpected versions: %v", versions)
+		}
+		for _, v := range versions {
+			if v.Created().IsZero() {
+				t.Errorf("failed to parse the date: %s", v.Date)
+			}
+		}
+	}
+	var l versionSummaryList
+	if versions, err := l.list(); err != nil || versions != nil {
+		t.Errorf("expected no versions, got %v, %v", versions, err)
+	}
+	v := VersionSummary{Date: "2017-07-14T11:40:00.000+09:00"}
+	if !v.Created().Equal(time.Date(2017, 7, 14, 2, 40, 0, 0, time.UTC)) {
+		t.Errorf("unexpected date: %v", v.Created())
+	}
+}
+
+// versionServer is the repository which has the versions of /public/a.ktr.
+type versionServer struct {
+	versions      []string
+	contents      map[string]string
+	ignoreRestore bool
+	requests      []string
+}
+
+func (s *versionServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	request := r.Method + " " + r.URL.Path
+	if r.URL.RawQuery != "" {
+		request += "?" + r.URL.RawQuery
+	}
+	s.requests = append(s.requests, request)
+	latest := s.versions[len(s.versions)-1]
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/api/repo/files/:public:a.ktr/versions":
+		var summaries []string
+		for _, v := range s.versions {
+			summaries = append(summaries, fmt.Sprintf(`{"id":%q,"author":"admin"}`, v))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"versionSummary":[` + strings.Join(summaries, ",") + `]}`))
+	case r.Method == "GET" && r.URL.Path == "/api/repo/files/:public:a.ktr":
+		version := r.URL.Query().Get("versionId")
+		if version == "" {
+			version = latest
+		}
+		w.Write([]byte(s.contents[version]))
+	case r.Method == "PUT" && strings.HasPrefix(r.URL.Path, "/api/repo/files/:public:a.ktr/versions/"):
+		if s.ignoreRestore {
+			return
+		}
+		restored := fmt.Sprintf("1.%d", len(s.versions))
+		s.contents[restored] = s.contents[strings.TrimPrefix(r.URL.Path, "/api/repo/files/:public:a.ktr/versions/")]
+		s.versions = append(s.versions, restored)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestGetFileVersion(t *testing.T) {
+	s := &versionServer{versions: []string{"1.0", "1.1"}, contents: map[string]string{"1.0": "first", "1.1": "second"}}
+	server := httptest.NewServer(s)
+	defer server.Close()
+	c := NewClient(server.URL, "admin", "password")
+
+	content, err := c.GetFileVersionContent("/public/a.ktr", "1.0")
+	if err != nil || string(content) != "first" {
+		t.Errorf("unexpected content: %s, %v", content, err)
+	}
+	dir, err := ioutil.TempDir("", "version")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "a.ktr")
+	if err := c.GetFileVersion("/public/a.ktr", "1.0", file); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := ioutil.ReadFile(file); string(data) != "first" {
+		t.Errorf("unexpected content: %s", data)
+	}
+	expected := []string{
+		"GET /api/repo/files/:public:a.ktr/versions",
+		"GET /api/repo/files/:public:a.ktr?versionId=1.0",
+		"GET /api/repo/files/:public:a.ktr/versions",
+		"GET /api/repo/files/:public:a.ktr?versionId=1.0",
+	}
+	if !reflect.DeepEqual(s.requests, expected) {
+		t.Errorf("expected %v but %v", expected, s.requests)
+	}
+
+	s.requests = nil
+	if content, err := c.GetFileVersionContent("/public/a.ktr", "9.9"); err == nil {
+		t.Errorf("expected error for the unknown version, got %s", content)
+	}
+	if len(s.requests) != 1 {
+		t.Errorf("the content of the unknown version should not be requested: %v", s.requests)
+	}
+}
+
+func TestRestoreFileVersion(t *testing.T) {
+	s := &versionServer{versions: []string{"1.0", "1.1"}, contents: map[string]string{"1.0": "first", "1.1": "second"}}
+	server := httptest.NewServer(s)
+	defer server.Close()
+	c := NewClient(server.URL, "admin", "password")
+
+	if err := c.RestoreFileVersion("/public/a.ktr", "1.0"); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"GET /api/repo/files/:public:a.ktr/versions",
+		"PUT /api/repo/files/:public:a.ktr/versions/1.0",
+		"GET /api/repo/files/:public:a.ktr/versions",
+	}
+	if !reflect.DeepEqual(s.requests, expected) {
+		t.Errorf("expected %v but %v", expected, s.requests)
+	}
+	if content, err := c.GetFileVersionContent("/public/a.ktr", ""); err != nil || string(content) != "first" {
+		t.Errorf("unexpected content after the restore: %s, %v", content, err)
+	}
+
+	s.ignoreRestore = true
+	if err := c.RestoreFileVersion("/public/a.ktr", "1.1"); err == nil {
+		t.Error("expected error if no version is added")
+	}
+	if err := c.RestoreFileVersion("/public/a.ktr", "9.9"); err == nil {
+		t.Error("expected error for the unknown version")
+	}
+}
@@ -0,0 +1,257 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gopkg.in/cheggaaa/pb.v1"
+)
+
+// downloadRetries is the number of the retries when the download is interrupted.
+var downloadRetries = 3
+
+// downloadRetryInterval is the interval before each retry, multiplied by the number of the attempts.
+var downloadRetryInterval = 2 * time.Second
+
+// transferIdleTimeout is the timeout of the transfer without any progress.
+var transferIdleTimeout = time.Minute
+
+// ErrTransferStalled is returned when the transfer makes no progress within the idle timeout.
+var ErrTransferStalled = errors.New("transfer stalled")
+
+// newTransport creates the transport with the timeouts shared by the API client and the file transfers.
+// The response headers are waited without the timeout, because the server sends them after the long operations
+// such as building the backup or importing the files. The stalled bodies are detected by idleTimeoutReader.
+func newTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		IdleConnTimeout:       90 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// idleTimeoutReader cancels the transfer if no data is read within the timeout.
+// The timer is stopped at the end of the data.
+type idleTimeoutReader struct {
+	r       io.Reader
+	timeout time.Duration
+	timer   *time.Timer
+	stalled int32
+}
+
+func newIdleTimeoutReader(r io.Reader, timeout time.Duration, cancel context.CancelFunc) *idleTimeoutReader {
+	reader := &idleTimeoutReader{r: r, timeout: timeout}
+	reader.timer = time.AfterFunc(timeout, func() {
+		atomic.StoreInt32(&reader.stalled, 1)
+		cancel()
+	})
+	return reader
+}
+
+func (r *idleTimeoutReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if atomic.LoadInt32(&r.stalled) == 1 {
+		return n, ErrTransferStalled
+	}
+	if err != nil {
+		r.timer.Stop()
+	} else if n > 0 {
+		r.timer.Reset(r.timeout)
+	}
+	return n, err
+}
+
+// newTransferBar creates the progress bar of the transfer with the bytes/sec and the ETA.
+// It returns nil if the progress is not shown.
+func (c *Client) newTransferBar(total int64, prefix string) *pb.ProgressBar {
+	if !c.ShowProgress {
+		return nil
+	}
+	bar := pb.New64(total).SetUnits(pb.U_BYTES)
+	bar.ShowSpeed = true
+	bar.Output = os.Stderr
+	bar.Prefix(prefix + " ")
+	return bar.Start()
+}
+
+// newRequest creates the HTTP request to the API.
+// It is used instead of resty to stream the large content.
+func (c *Client) newRequest(method string, apiPath string, query url.Values, body io.Reader) (*http.Request, error) {
+	u := strings.TrimSuffix(c.url, "/") + "/" + apiPath
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(c.User, c.Password)
+	return req, nil
+}
+
+// upload sends the file as the request body.
+// It returns the status code of the response.
+func (c *Client) upload(method string, apiPath string, file string) (int, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	var body io.Reader = f
+	bar := c.newTransferBar(stat.Size(), "Upload "+stat.Name())
+	if bar != nil {
+		body = bar.NewProxyReader(f)
+		defer bar.Finish()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reader := newIdleTimeoutReader(body, transferIdleTimeout, cancel)
+	defer reader.timer.Stop()
+	req, err := c.newRequest(method, apiPath, nil, reader)
+	if err != nil {
+		return 0, err
+	}
+	req.ContentLength = stat.Size()
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		if atomic.LoadInt32(&reader.stalled) == 1 {
+			return 0, ErrTransferStalled
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+// download saves the response body to the file.
+// The interrupted or stalled download is retried, and resumed with the Range header if the server supports.
+// name is shown in the progress bar instead of the destination, which may be a temporary file.
+// It returns the response with the closed body, or the error response without saving the file.
+func (c *Client) download(name string, apiPath string, query url.Values, header http.Header, destination string) (resp *http.Response, err error) {
+	out, err := os.Create(destination)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		out.Close()
+		if err != nil || resp.StatusCode/100 != 2 {
+			os.Remove(destination)
+		}
+	}()
+
+	var bar *pb.ProgressBar
+	defer func() {
+		if bar != nil {
+			bar.Finish()
+		}
+	}()
+	var offset int64
+	var lastErr error
+	for attempt := 0; attempt <= downloadRetries; attempt++ {
+		if attempt > 0 {
+			c.Logger.Warn("Retrying the interrupted download.", zap.String("apiPath", apiPath), zap.Int("attempt", attempt), zap.Int64("offset", offset), zap.Error(lastErr))
+			time.Sleep(time.Duration(attempt) * downloadRetryInterval)
+		}
+		req, err := c.newRequest("GET", apiPath, query, nil)
+		if err != nil {
+			return nil, err
+		}
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		if offset > 0 {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		resp, err := c.httpClient.Do(req.WithContext(ctx))
+		if err != nil {
+			cancel()
+			lastErr = err
+			continue
+		}
+		switch {
+		case resp.StatusCode == http.StatusPartialContent && offset > 0:
+			// resumed
+		case resp.StatusCode == http.StatusOK:
+			// the first attempt, or the server ignored the Range header.
+			if offset > 0 {
+				if _, err := out.Seek(0, io.SeekStart); err != nil {
+					resp.Body.Close()
+					cancel()
+					return nil, err
+				}
+				if err := out.Truncate(0); err != nil {
+					resp.Body.Close()
+					cancel()
+					return nil, err
+				}
+				offset = 0
+			}
+		default:
+			resp.Body.Close()
+			cancel()
+			return resp, nil
+		}
+		if bar == nil {
+			total := resp.ContentLength
+			if total > 0 {
+				total += offset
+			} else {
+				total = 0
+			}
+			bar = c.newTransferBar(total, "Download "+name)
+		}
+		if bar != nil {
+			bar.Set64(offset)
+		}
+		var w io.Writer = out
+		if bar != nil {
+			w = io.MultiWriter(out, bar)
+		}
+		reader := newIdleTimeoutReader(resp.Body, transferIdleTimeout, cancel)
+		n, err := io.Copy(w, reader)
+		reader.timer.Stop()
+		resp.Body.Close()
+		cancel()
+		offset += n
+		if err == nil && (resp.ContentLength < 0 || n == resp.ContentLength) {
+			return resp, nil
+		}
+		if err == nil {
+			err = io.ErrUnexpectedEOF
+		}
+		lastErr = err
+		if resp.Header.Get("Accept-Ranges") != "bytes" && resp.StatusCode != http.StatusPartialContent {
+			// restart from the beginning because the server does not support the ranges.
+			offset = 0
+			if _, err := out.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+			if err := out.Truncate(0); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return nil, errors.Wrap(lastErr, "download failed after retries")
+}
@@ -0,0 +1,141 @@
+package client
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDownloadResume(t *testing.T) {
+	content := "0123456789"
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, _ := r.BasicAuth(); user != "admin" || password != "password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		rng := r.Header.Get("Range")
+		ranges = append(ranges, rng)
+		w.Header().Set("Accept-Ranges", "bytes")
+		if rng == "" {
+			// interrupted in the middle
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(content[:4]))
+			return
+		}
+		offset, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rng, "bytes="), "-"))
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, len(content)-1, len(content)))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte(content[offset:]))
+	}))
+	defer server.Close()
+	downloadRetryInterval = 0
+
+	dir, err := ioutil.TempDir("", "download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	c := NewClient(server.URL, "admin", "password")
+	file := filepath.Join(dir, "out")
+	resp, err := c.download("backup", "api/repo/files/backup", nil, nil, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		t.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+	if data, _ := ioutil.ReadFile(file); string(data) != content {
+		t.Errorf("expected %s, got %s", content, data)
+	}
+	if len(ranges) != 2 || ranges[1] != "bytes=4-" {
+		t.Errorf("unexpected requests: %v", ranges)
+	}
+
+	c = NewClient(server.URL, "admin", "wrong")
+	resp, err = c.download("backup", "api/repo/files/backup", nil, nil, file)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("unexpected response: %v, %v", resp, err)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("the file of the error response should be removed: %v", err)
+	}
+}
+
+func TestDownloadStalled(t *testing.T) {
+	content := "0123456789"
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		w.WriteHeader(http.StatusOK)
+		if requests == 1 {
+			// stalled in the middle
+			w.Write([]byte(content[:4]))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+	downloadRetryInterval = 0
+	transferIdleTimeout = 100 * time.Millisecond
+	defer func() { transferIdleTimeout = time.Minute }()
+
+	dir, err := ioutil.TempDir("", "download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	c := NewClient(server.URL, "admin", "password")
+	file := filepath.Join(dir, "out")
+	resp, err := c.download("backup", "api/repo/files/backup", nil, nil, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+	if data, _ := ioutil.ReadFile(file); string(data) != content {
+		t.Errorf("expected %s, got %s", content, data)
+	}
+	if requests != 2 {
+		t.Errorf("the stalled download should be retried: %d requests", requests)
+	}
+}
+
+func TestDownloadSlowResponse(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		// the server builds the content before sending the headers.
+		time.Sleep(300 * time.Millisecond)
+		w.Write([]byte("backup"))
+	}))
+	defer server.Close()
+	transferIdleTimeout = 100 * time.Millisecond
+	defer func() { transferIdleTimeout = time.Minute }()
+
+	dir, err := ioutil.TempDir("", "download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	c := NewClient(server.URL, "admin", "password")
+	file := filepath.Join(dir, "out")
+	if _, err := c.download("backup", "api/repo/files/backup", nil, nil, file); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := ioutil.ReadFile(file); string(data) != "backup" || requests != 1 {
+		t.Errorf("unexpected result: %s, %d requests", data, requests)
+	}
+}
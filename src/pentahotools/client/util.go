@@ -2,6 +2,7 @@ package client
 
 import (
 	"io/ioutil"
+	"net/http"
 	"net/url"
 	"os"
 	"regexp"
@@ -62,16 +63,26 @@ func (h *DownloadHelper) findFilenameFromContentDisposition(contentDisposition s
 }
 
 func (h *DownloadHelper) MoveTemporaryFileToDestination(resp *resty.Response) (string, error) {
+	return h.moveTemporaryFile(func() string {
+		if h.FilenameFunc == nil {
+			return h.findFilenameFromContentDisposition(resp.Header().Get("Content-Disposition"))
+		}
+		return h.FilenameFunc(resp)
+	})
+}
+
+// MoveDownloadedFileToDestination moves the file downloaded without resty to the destination.
+func (h *DownloadHelper) MoveDownloadedFileToDestination(header http.Header) (string, error) {
+	return h.moveTemporaryFile(func() string {
+		return h.findFilenameFromContentDisposition(header.Get("Content-Disposition"))
+	})
+}
+
+func (h *DownloadHelper) moveTemporaryFile(filenameFunc func() string) (string, error) {
 	fixedDestination := h.destination
 	stat, err := os.Stat(fixedDestination)
 	if fixedDestination == "" || (os.IsExist(err) && stat.IsDir()) {
-		var filename string
-		if h.FilenameFunc == nil {
-			contentDisposition := resp.Header().Get("Content-Disposition")
-			filename = h.findFilenameFromContentDisposition(contentDisposition)
-		} else {
-			filename = h.FilenameFunc(resp)
-		}
+		filename := filenameFunc()
 		if strings.HasSuffix(fixedDestination, "/") {
 			fixedDestination = fixedDestination + filename
 		} else {
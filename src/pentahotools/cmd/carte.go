@@ -1,8 +1,10 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
 	"os"
+	"strings"
 
 	"time"
 
@@ -10,6 +12,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	client "github.com/uphy/pentahotools/client"
+	"github.com/uphy/pentahotools/table"
 )
 
 func init() {
@@ -71,54 +74,138 @@ func init() {
 			if err != nil {
 				return err
 			}
-			status.Print(client.NewIndentWriter(os.Stdout))
+			writer := client.NewIndentWriter(os.Stdout)
+			if summary, _ := cmd.Flags().GetBool("summary"); !summary {
+				status.Print(writer)
+			}
+			if status.IsFailed() {
+				printFailureSummary(writer, status)
+			}
 			return nil
 		},
 	}
+	statusCmd.Flags().BoolP("summary", "s", false, "Print only the failure summary.")
 	statusCmd.Aliases = []string{"ls"}
 	carteCmd.AddCommand(statusCmd)
-	carteCmd.AddCommand(&cobra.Command{
+	runCmd := &cobra.Command{
 		Use:   "run",
 		Short: "Run the specified job or transformation.",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) != 1 {
 				return errors.New("specify a job")
 			}
-			jobID, err := Client.Run(args[0], client.LogLevels.Debug)
+			file, err := Client.ResolveKettleFile(args[0])
 			if err != nil {
-				return errors.Wrap(err, "job execution failure")
+				return err
 			}
-			status, err := Client.GetStatus(jobID, "", 0)
+			params := cmd.Flags().Lookup("param").Value.(*keyValueFlag).values
+			validate, _ := cmd.Flags().GetBool("validate")
+			prompt, _ := cmd.Flags().GetBool("prompt")
+			if validate || prompt {
+				declared, err := Client.GetParameters(file)
+				if err != nil {
+					return errors.Wrap(err, "getting parameters failure")
+				}
+				unknown, _ := client.CheckParameters(declared, params)
+				if len(unknown) > 0 {
+					return fmt.Errorf("unknown parameters: %s", strings.Join(unknown, ", "))
+				}
+				if prompt {
+					promptParameters(declared, params)
+				}
+				_, missing := client.CheckParameters(declared, params)
+				for _, name := range missing {
+					fmt.Printf("Warning: required parameter '%s' is not specified.\n", name)
+				}
+			}
+			jobID, err := Client.Run(file, client.LogLevels.Debug, params)
 			if err != nil {
-				return errors.Wrap(err, "getting status failure")
+				return errors.Wrap(err, "job execution failure")
 			}
+			summary, _ := cmd.Flags().GetBool("summary")
+			writer := client.NewIndentWriter(os.Stdout)
 			for {
-				status.Print(client.NewIndentWriter(os.Stdout))
+				status, err := Client.GetStatus(jobID, "", 0)
+				if err != nil {
+					return errors.Wrap(err, "getting status failure")
+				}
+				if !summary {
+					status.Print(writer)
+				}
 				if status.IsFinished() {
+					if status.IsFailed() {
+						printFailureSummary(writer, status)
+						return errors.New("job/transformation failed")
+					}
 					break
 				}
 				time.Sleep(time.Second)
 			}
 			return nil
 		},
+	}
+	runCmd.Flags().VarP(newKeyValueFlag(), "param", "P", "Parameter of the job/transformation. (e.g., -P name=value)")
+	runCmd.Flags().BoolP("validate", "v", true, "Validate the parameters with the declared parameters. (--validate=false to skip)")
+	runCmd.Flags().BoolP("prompt", "i", false, "Prompt for the values of the parameters which are not specified. (implies --validate)")
+	runCmd.Flags().BoolP("summary", "s", false, "Print only the failure summary instead of the whole status.")
+	carteCmd.AddCommand(runCmd)
+
+	carteCmd.AddCommand(&cobra.Command{
+		Use:   "params",
+		Short: "List the parameters of the specified job or transformation.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("specify a job or transformation")
+			}
+			params, err := Client.GetParameters(args[0])
+			if err != nil {
+				return err
+			}
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"Name", "Default", "Description"})
+			for _, p := range params {
+				table.Append([]string{p.Name, p.DefaultValue, p.Description})
+			}
+			table.Render()
+			return nil
+		},
 	})
 
 	removeCmd := &cobra.Command{
 		Use:   "remove",
 		Short: "Remove the specified job/transformation.",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			archiveDir, _ := cmd.Flags().GetString("archive-dir")
+			compress, _ := cmd.Flags().GetBool("gzip")
+			archive := func(id, name string) error {
+				if archiveDir == "" {
+					return nil
+				}
+				file, err := Client.ArchiveLog(id, name, &client.ArchiveOptions{Dir: archiveDir, Compress: compress})
+				if err != nil {
+					return errors.Wrap(err, "archiving log failure")
+				}
+				fmt.Println("Archived log to " + file)
+				return nil
+			}
 			if all, _ := cmd.Flags().GetBool("all"); all {
 				status, err := Client.GetStatusCarteServer()
 				if err != nil {
 					return errors.Wrap(err, "getting job list failure")
 				}
 				for _, job := range status.JobStatusList.List {
+					if err := archive(job.ID, job.Name); err != nil {
+						return err
+					}
 					err = Client.JobClient.Remove(job.ID, job.Name)
 					if err != nil {
 						return errors.Wrap(err, "job removal failure")
 					}
 				}
 				for _, trans := range status.TransformationStatusList.List {
+					if err := archive(trans.ID, trans.Name); err != nil {
+						return err
+					}
 					err = Client.TransformationClient.Remove(trans.ID, trans.Name)
 					if err != nil {
 						return errors.Wrap(err, "transformation removal failure")
@@ -130,6 +217,9 @@ func init() {
 				}
 				var err error
 				id, name := client.ParseIDAndName(args[0])
+				if err := archive(id, name); err != nil {
+					return err
+				}
 				err = Client.RemoveJobOrTransformation(id, name)
 				if err != nil {
 					return errors.Wrap(err, "job/transformation removal failure")
@@ -139,6 +229,242 @@ func init() {
 		},
 	}
 	removeCmd.Flags().BoolP("all", "a", false, "Remove all finished job/transformations.")
+	removeCmd.Flags().StringP("archive-dir", "d", "", "Archive the logs to the directory before removal.")
+	removeCmd.Flags().BoolP("gzip", "z", false, "Compress the archived logs with gzip.")
 	removeCmd.Aliases = []string{"rm"}
 	carteCmd.AddCommand(removeCmd)
+
+	archiveCmd := &cobra.Command{
+		Use:   "archive",
+		Short: "Archive the logs of the specified job/transformation.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, _ := cmd.Flags().GetString("dir")
+			compress, _ := cmd.Flags().GetBool("gzip")
+			if dir == "" {
+				return errors.New("specify the archive directory")
+			}
+			options := &client.ArchiveOptions{Dir: dir, Compress: compress}
+			var targets [][2]string
+			if all, _ := cmd.Flags().GetBool("all"); all {
+				status, err := Client.GetStatusCarteServer()
+				if err != nil {
+					return errors.Wrap(err, "getting job list failure")
+				}
+				for _, job := range status.JobStatusList.List {
+					targets = append(targets, [2]string{job.ID, job.Name})
+				}
+				for _, trans := range status.TransformationStatusList.List {
+					targets = append(targets, [2]string{trans.ID, trans.Name})
+				}
+			} else {
+				if len(args) != 1 {
+					return errors.New("specify a job or transformation")
+				}
+				id, name := client.ParseIDAndName(args[0])
+				targets = append(targets, [2]string{id, name})
+			}
+			for _, target := range targets {
+				file, err := Client.ArchiveLog(target[0], target[1], options)
+				if err != nil {
+					return errors.Wrap(err, "archiving log failure")
+				}
+				fmt.Println("Archived log to " + file)
+			}
+			return nil
+		},
+	}
+	archiveCmd.Flags().BoolP("all", "a", false, "Archive the logs of all job/transformations.")
+	archiveCmd.Flags().StringP("dir", "d", "", "The archive directory.")
+	archiveCmd.Flags().BoolP("gzip", "z", false, "Compress the archived logs with gzip.")
+	carteCmd.AddCommand(archiveCmd)
+
+	compareCmd := &cobra.Command{
+		Use:   "compare",
+		Short: "Compare the steps of the two transformation executions.",
+		Long:  `Compare the steps of the two transformation executions.  Each execution is specified by ID/name or a status snapshot written by 'archive' command.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return errors.New("specify the base and target executions")
+			}
+			base, err := getTransformationStatus(args[0])
+			if err != nil {
+				return err
+			}
+			target, err := getTransformationStatus(args[1])
+			if err != nil {
+				return err
+			}
+			threshold, _ := cmd.Flags().GetFloat64("threshold")
+			out, _ := cmd.Flags().GetString("out")
+			writer, err := table.NewWriter(out, map[int]string{})
+			if err != nil {
+				return err
+			}
+			defer writer.Close()
+			writer.WriteHeader(&[]string{"Step", "Read", "Written", "Rejected", "Seconds", "Speed", "Change", "Slower"})
+			for _, c := range client.CompareSteps(base, target) {
+				var b, t client.StepStatus
+				if c.Base != nil {
+					b = *c.Base
+				}
+				if c.Target != nil {
+					t = *c.Target
+				}
+				slower := ""
+				if c.IsSlower(threshold) {
+					slower = "*"
+				}
+				writer.WriteRow(&[]string{
+					c.Name,
+					fmt.Sprintf("%d -> %d (%+d)", b.LinesRead, t.LinesRead, t.LinesRead-b.LinesRead),
+					fmt.Sprintf("%d -> %d (%+d)", b.LinesWritten, t.LinesWritten, t.LinesWritten-b.LinesWritten),
+					fmt.Sprintf("%d -> %d (%+d)", b.LinesRejected, t.LinesRejected, t.LinesRejected-b.LinesRejected),
+					fmt.Sprintf("%.1f -> %.1f (%+.1f)", b.Seconds, t.Seconds, t.Seconds-b.Seconds),
+					fmt.Sprintf("%.0f -> %.0f (%+.0f)", b.ParseSpeed(), t.ParseSpeed(), t.ParseSpeed()-b.ParseSpeed()),
+					fmt.Sprintf("%+.1f %%", c.SecondsChange()),
+					slower,
+				})
+			}
+			return nil
+		},
+	}
+	compareCmd.Flags().Float64P("threshold", "t", 20, "Mark the steps slower than the threshold(%).")
+	compareCmd.Flags().StringP("out", "o", table.ConsoleOutput, "Output file(csv/xlsx).")
+	carteCmd.AddCommand(compareCmd)
+
+	timelineCmd := &cobra.Command{
+		Use:   "timeline",
+		Short: "Show the timeline of the job entries of the specified job.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("specify a job")
+			}
+			id, name := client.ParseIDAndName(args[0])
+			status, err := Client.GetStatus(id, name, 0)
+			if err != nil {
+				return err
+			}
+			job, ok := status.(*client.JobStatus)
+			if !ok {
+				return errors.New("not a job: " + args[0])
+			}
+			timeline := client.ParseJobTimeline(job.LoggingString)
+			if len(timeline) == 0 {
+				return errors.New("no job entries found in the log.  run the job with 'Basic' or more detailed log level")
+			}
+			width, _ := cmd.Flags().GetInt("width")
+			out, _ := cmd.Flags().GetString("out")
+			htmlFile, _ := cmd.Flags().GetString("html")
+			files := []string{out}
+			if htmlFile != "" {
+				files = append(files, htmlFile)
+			}
+			for _, file := range files {
+				if err := writeTimeline(file, timeline, width); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	timelineCmd.Flags().StringP("out", "o", table.ConsoleOutput, "Output file(csv/xlsx/html).")
+	timelineCmd.Flags().String("html", "", "Also write the timeline to the html file.")
+	timelineCmd.Flags().IntP("width", "w", 50, "The width of the timeline bars.")
+	carteCmd.AddCommand(timelineCmd)
+}
+
+// promptParameters prompts for the values of the declared parameters which are not specified.
+// An empty input keeps the default value.
+func promptParameters(declared []client.Parameter, params map[string]string) {
+	reader := bufio.NewReader(os.Stdin)
+	for _, p := range declared {
+		if _, ok := params[p.Name]; ok {
+			continue
+		}
+		if p.Description != "" {
+			fmt.Printf("%s (%s) [%s]: ", p.Name, p.Description, p.DefaultValue)
+		} else {
+			fmt.Printf("%s [%s]: ", p.Name, p.DefaultValue)
+		}
+		value, _ := reader.ReadString('\n')
+		value = strings.TrimRight(value, "\r\n")
+		if value != "" {
+			params[p.Name] = value
+		}
+	}
+}
+
+// printFailureSummary prints the compact summary of the failed job/transformation.
+func printFailureSummary(writer *client.IndentWriter, status client.Status) {
+	writer.Println("# Failure Summary")
+	status.PrintFailureSummary(writer)
+}
+
+// getTransformationStatus gets the status of the transformation from the carte server or a status snapshot file.
+func getTransformationStatus(s string) (*client.TransformationStatus, error) {
+	if _, err := os.Stat(s); err == nil {
+		return client.LoadStatusSnapshot(s)
+	}
+	id, name := client.ParseIDAndName(s)
+	status, err := Client.GetStatus(id, name, 0)
+	if err != nil {
+		return nil, err
+	}
+	trans, ok := status.(*client.TransformationStatus)
+	if !ok {
+		return nil, errors.New("not a transformation: " + s)
+	}
+	return trans, nil
+}
+
+// writeTimeline writes the timeline of the job entries with Gantt-style bars.
+func writeTimeline(file string, timeline []client.TimelineEntry, width int) error {
+	writer, err := table.NewWriter(file, map[int]string{})
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+	start, end := timeline[0].Start, timeline[0].End
+	for _, e := range timeline {
+		if e.Start.Before(start) {
+			start = e.Start
+		}
+		if e.End.After(end) {
+			end = e.End
+		}
+	}
+	total := end.Sub(start)
+	position := func(t time.Time) int {
+		if total <= 0 {
+			return 0
+		}
+		return int(float64(t.Sub(start)) / float64(total) * float64(width))
+	}
+	writer.WriteHeader(&[]string{"Entry", "Job", "Start", "End", "Seconds", "Result", "Parallel", "Timeline"})
+	for _, e := range timeline {
+		from, to := position(e.Start), position(e.End)
+		if to <= from {
+			to = from + 1
+		}
+		bar := strings.Repeat(" ", from) + strings.Repeat("#", to-from)
+		result := e.Result
+		if result == "" {
+			result = "(running)"
+		}
+		parallel := ""
+		if e.Parallel {
+			parallel = "yes"
+		}
+		writer.WriteRow(&[]string{
+			strings.Repeat("  ", e.Depth) + e.Name,
+			e.Job,
+			e.Start.Format("15:04:05"),
+			e.End.Format("15:04:05"),
+			fmt.Sprintf("%.0f", e.Duration().Seconds()),
+			result,
+			parallel,
+			bar,
+		})
+	}
+	return nil
 }
@@ -1,12 +1,22 @@
 package cmd
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/uphy/pentahotools/batch"
 	"github.com/uphy/pentahotools/client"
+	"github.com/uphy/pentahotools/table"
+	"gopkg.in/cheggaaa/pb.v1"
+	yaml "gopkg.in/yaml.v2"
 )
 
 func init() {
@@ -32,36 +42,213 @@ func init() {
 			} else {
 				path = "/"
 			}
-			showHidden, _ := cmd.Flags().GetBool("showHidden")
+			showHidden, _ := cmd.Flags().GetBool("show-hidden")
+			if deprecated, _ := cmd.Flags().GetBool("showHidden"); deprecated {
+				showHidden = true
+			}
 			depth, _ := cmd.Flags().GetInt("depth")
+			format, _ := cmd.Flags().GetString("format")
 			root, err := Client.Tree(path, depth, showHidden)
 			if err != nil {
 				return err
 			}
-			root.Print()
+			switch format {
+			case "tree":
+				root.Print()
+			case "json":
+				data, err := json.MarshalIndent(root.Node(), "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(data))
+			case "yaml":
+				data, err := yaml.Marshal(root.Node())
+				if err != nil {
+					return err
+				}
+				fmt.Print(string(data))
+			case "table":
+				writer, err := table.NewWriter(table.ConsoleOutput, map[int]string{})
+				if err != nil {
+					return err
+				}
+				defer writer.Close()
+				writer.WriteHeader(&fileRowHeader)
+				root.Walk(func(entry *client.FileEntry) error {
+					writer.WriteRow(fileRow(&entry.File))
+					return nil
+				})
+			case "csv":
+				writer := csv.NewWriter(os.Stdout)
+				writer.Write(fileRowHeader)
+				root.Walk(func(entry *client.FileEntry) error {
+					return writer.Write(*fileRow(&entry.File))
+				})
+				writer.Flush()
+				return writer.Error()
+			default:
+				return errors.New("unsupported format: " + format)
+			}
 			return nil
 		},
 	}
-	treeCmd.Flags().BoolP("showHidden", "s", false, "Show hidden files")
-	treeCmd.Flags().IntP("depth", "d", 1, "The depth of the tree")
+	treeCmd.Flags().BoolP("show-hidden", "s", false, "Show hidden files")
+	treeCmd.Flags().Bool("showHidden", false, "Show hidden files")
+	treeCmd.Flags().MarkDeprecated("showHidden", "use --show-hidden instead")
+	treeCmd.Flags().IntP("depth", "d", 1, "The depth of the tree (-1 means unlimited)")
+	treeCmd.Flags().StringP("format", "f", "tree", "The output format. (tree/json/yaml/table/csv)")
 	treeCmd.Aliases = []string{"ls"}
 	fileCmd.AddCommand(treeCmd)
 
+	// file find
+	findCmd := &cobra.Command{
+		Use:   "find",
+		Short: "Find the files in the repository tree.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return errors.New("too many arguments")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "/"
+			if len(args) > 0 {
+				path = args[0]
+			}
+			filter := &client.FileFilter{}
+			filter.Name, _ = cmd.Flags().GetString("name")
+			filter.Type, _ = cmd.Flags().GetString("type")
+			filter.Locked, _ = cmd.Flags().GetBool("locked")
+			filter.Hidden, _ = cmd.Flags().GetBool("hidden")
+			for _, f := range []struct {
+				name string
+				size *int64
+			}{{"larger-than", &filter.LargerThan}, {"smaller-than", &filter.SmallerThan}} {
+				if s, _ := cmd.Flags().GetString(f.name); s != "" {
+					size, err := client.ParseSize(s)
+					if err != nil {
+						return err
+					}
+					*f.size = size
+				}
+			}
+			for _, f := range []struct {
+				name string
+				date *time.Time
+			}{{"created-before", &filter.CreatedBefore}, {"created-after", &filter.CreatedAfter}} {
+				if s, _ := cmd.Flags().GetString(f.name); s != "" {
+					date, err := time.ParseInLocation("2006-01-02", s, time.Local)
+					if err != nil {
+						return errors.New("invalid date: " + s)
+					}
+					*f.date = date
+				}
+			}
+			if err := filter.Validate(); err != nil {
+				return err
+			}
+			depth, _ := cmd.Flags().GetInt("depth")
+			root, err := Client.Tree(path, depth, filter.Hidden)
+			if err != nil {
+				return err
+			}
+			files := root.Find(filter)
+
+			long, _ := cmd.Flags().GetBool("long")
+			out, _ := cmd.Flags().GetString("out")
+			if !long && out == table.ConsoleOutput {
+				for _, file := range files {
+					fmt.Println(file.Path)
+				}
+				return nil
+			}
+			writer, err := table.NewWriter(out, map[int]string{})
+			if err != nil {
+				return err
+			}
+			defer writer.Close()
+			writer.WriteHeader(&fileRowHeader)
+			for _, file := range files {
+				writer.WriteRow(fileRow(&file))
+			}
+			return nil
+		},
+	}
+	findCmd.Flags().StringP("name", "n", "", "The glob pattern of the file name. (e.g., '*.ktr')")
+	findCmd.Flags().StringP("type", "t", "", "The type of the file. (file/folder)")
+	findCmd.Flags().String("larger-than", "", "Find the files larger than the size. (e.g., 1MB)")
+	findCmd.Flags().String("smaller-than", "", "Find the files smaller than the size. (e.g., 10KB)")
+	findCmd.Flags().String("created-before", "", "Find the files created before the date. (e.g., 2017-01-01)")
+	findCmd.Flags().String("created-after", "", "Find the files created after the date. (e.g., 2017-01-01)")
+	findCmd.Flags().Bool("locked", false, "Find the locked files.")
+	findCmd.Flags().Bool("hidden", false, "Find the hidden files.")
+	findCmd.Flags().IntP("depth", "d", -1, "The depth of the tree to search. (-1 means unlimited)")
+	findCmd.Flags().Bool("long", false, "Show the details of the files as a table.")
+	findCmd.Flags().StringP("out", "o", table.ConsoleOutput, "Output file(csv/xlsx/html).")
+	fileCmd.AddCommand(findCmd)
+
 	// file backup
-	fileCmd.AddCommand(&cobra.Command{
+	backupCmd := &cobra.Command{
 		Use:   "backup",
-		Short: "Backup Pentaho system.",
+		Short: "Backup Pentaho system.  If the output is a directory, the backup is saved with the timestamped name.",
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) != 1 {
-				return errors.New("specify output zip path")
+				return errors.New("specify output zip path or directory")
 			}
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			err := Client.Backup(args[0])
+			keep, _ := cmd.Flags().GetInt("keep")
+			keepDaily, _ := cmd.Flags().GetInt("keep-daily")
+			keepWeekly, _ := cmd.Flags().GetInt("keep-weekly")
+			var result *batch.BackupResult
+			err := withProgress(func() (err error) {
+				result, err = batch.Backup(args[0], &batch.BackupOptions{
+					Keep:       keep,
+					KeepDaily:  keepDaily,
+					KeepWeekly: keepWeekly,
+				}, &Client, Client.Logger)
+				return err
+			})
+			if result != nil {
+				fmt.Printf("Saved the backup of %d files to %s\n", result.Entries, result.File)
+				for _, file := range result.Deleted {
+					fmt.Printf("Deleted the old backup %s\n", file)
+				}
+			}
 			return err
 		},
+	}
+	backupCmd.Flags().IntP("keep", "k", 0, "Number of the latest backups to keep in the output directory.")
+	backupCmd.Flags().Int("keep-daily", 0, "Number of the days to keep the latest backup of the day in the output directory.")
+	backupCmd.Flags().Int("keep-weekly", 0, "Number of the weeks to keep the latest backup of the week in the output directory.")
+	backupCmd.AddCommand(&cobra.Command{
+		Use:   "verify",
+		Short: "Verify that the backup zip can be read and contains the manifest.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errors.New("specify backup zip paths")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var failed int
+			for _, file := range args {
+				entries, err := batch.VerifyBackup(file)
+				if err != nil {
+					failed++
+					fmt.Printf("%s: broken: %s\n", file, err)
+					continue
+				}
+				fmt.Printf("%s: OK (%d files)\n", file, entries)
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d backups are broken", failed, len(args))
+			}
+			return nil
+		},
 	})
+	fileCmd.AddCommand(backupCmd)
 
 	// file restore
 	var restoreCmd = &cobra.Command{
@@ -93,12 +280,13 @@ func init() {
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			err := Client.PutFile(args[0], args[1])
-			return err
+			return withProgress(func() error {
+				return Client.PutFile(args[0], args[1])
+			})
 		},
 	})
 	// file get
-	fileCmd.AddCommand(&cobra.Command{
+	getCmd := &cobra.Command{
 		Use:   "get",
 		Short: "Get a file from the repository.",
 		PreRunE: func(cmd *cobra.Command, args []string) error {
@@ -108,10 +296,17 @@ func init() {
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			err := Client.GetFile(args[0], args[1])
-			return err
+			version, _ := cmd.Flags().GetString("version")
+			return withProgress(func() error {
+				if version != "" {
+					return Client.GetFileVersion(args[0], version, args[1])
+				}
+				return Client.GetFile(args[0], args[1])
+			})
 		},
-	})
+	}
+	getCmd.Flags().StringP("version", "v", "", "Get the previous version of the file. (See 'file versions')")
+	fileCmd.AddCommand(getCmd)
 	// file download
 	downloadCmd := &cobra.Command{
 		Use:   "download",
@@ -135,7 +330,11 @@ func init() {
 			}
 			withManifest, _ := cmd.Flags().GetBool("manifest")
 			overwrite, _ := cmd.Flags().GetBool("overwrite")
-			path, err := Client.DownloadFile(repositoryFile, destination, withManifest, overwrite)
+			var path string
+			err := withProgress(func() (err error) {
+				path, err = Client.DownloadFile(repositoryFile, destination, withManifest, overwrite)
+				return err
+			})
 			if err != nil {
 				return err
 			}
@@ -151,34 +350,182 @@ func init() {
 	// file import
 	importCmd := &cobra.Command{
 		Use:   "import",
-		Short: "Attempts to import all files from the zip archive or single file.  A log file is produced at the end of import.",
+		Short: "Attempts to import all files from the zip archive, the directory or single file.  The directory is packaged into a zip before the import.",
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) != 2 {
-				return errors.New("specify a upload file path and destination repository path")
+				return errors.New("specify a upload file/directory path and destination repository path")
 			}
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			_, filename := filepath.Split(args[0])
-			err := Client.ImportFile(args[0], args[1], &client.ImportParameters{
-				OverwriteFile:           true,
-				LogLevel:                "ERROR",
-				FileNameOverride:        filename,
-				RetainOwnership:         true,
-				OverwriteACLPermissions: false,
-				ApplyACLPermissions:     false,
-				Charset:                 "",
+			overwrite, _ := cmd.Flags().GetBool("overwrite")
+			logLevel, _ := cmd.Flags().GetString("loglevel")
+			fileName, _ := cmd.Flags().GetString("file-name")
+			retainOwnership, _ := cmd.Flags().GetBool("retain-ownership")
+			applyACL, _ := cmd.Flags().GetBool("apply-acl")
+			overwriteACL, _ := cmd.Flags().GetBool("overwrite-acl")
+			charset, _ := cmd.Flags().GetString("charset")
+			logFile, _ := cmd.Flags().GetString("log")
+			if !isImportLogLevel(logLevel) {
+				return fmt.Errorf("unsupported log level: %s (%s)", logLevel, strings.Join(client.ImportLogLevels, "/"))
+			}
+			file := args[0]
+			if fileName == "" {
+				fileName = filepath.Base(file)
+			}
+			if info, err := os.Stat(file); err != nil {
+				return err
+			} else if info.IsDir() {
+				aclFile, _ := cmd.Flags().GetString("acl")
+				if aclFile != "" && !cmd.Flags().Changed("apply-acl") {
+					applyACL = true
+				}
+				tmpFile, err := ioutil.TempFile("", "import")
+				if err != nil {
+					return err
+				}
+				tmpFile.Close()
+				defer os.Remove(tmpFile.Name())
+				count, err := batch.PackageDirectory(file, tmpFile.Name(), &batch.PackageOptions{
+					ImportDir: args[1],
+					ACLFile:   aclFile,
+					User:      user,
+				})
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Packaged %d files in %s.\n", count, file)
+				file = tmpFile.Name()
+				if filepath.Ext(fileName) != ".zip" {
+					fileName += ".zip"
+				}
+			}
+			result, err := Client.ImportFile(file, args[1], &client.ImportParameters{
+				OverwriteFile:           overwrite,
+				LogLevel:                strings.ToUpper(logLevel),
+				FileNameOverride:        fileName,
+				RetainOwnership:         retainOwnership,
+				OverwriteACLPermissions: overwriteACL,
+				ApplyACLPermissions:     applyACL,
+				Charset:                 charset,
 			})
 			if err != nil {
 				return err
 			}
-			return nil
+			if logFile != "" {
+				if err := ioutil.WriteFile(logFile, []byte(result.Log), 0644); err != nil {
+					return err
+				}
+			}
+			return printImportResult(result)
 		},
 	}
-	//importCmd.Flags().BoolP("overwrite", "o", false, "overwrite if exist")
-	//importCmd.Flags().StringP("loglevel", "L", string(client.LogLevels.Basic), "log level")
+	importCmd.Flags().BoolP("overwrite", "o", true, "Overwrite the existing files.")
+	importCmd.Flags().StringP("loglevel", "L", "INFO", "Level of the import log. ("+strings.Join(client.ImportLogLevels, "/")+")  The imported files are reported with INFO or lower.")
+	importCmd.Flags().String("file-name", "", "Name of the imported file.  Defaults to the name of the uploaded file.")
+	importCmd.Flags().Bool("retain-ownership", true, "Retain the owners of the files in the manifest.")
+	importCmd.Flags().Bool("apply-acl", false, "Apply the ACLs in the manifest.")
+	importCmd.Flags().Bool("overwrite-acl", false, "Overwrite the ACLs of the existing files with the ones in the manifest.")
+	importCmd.Flags().String("charset", "", "Charset of the imported files.")
+	importCmd.Flags().String("log", "", "Save the import log returned by the server to the file.")
+	importCmd.Flags().String("acl", "", "YAML file of the ACLs (See 'file acl export') to write to the manifest when importing a directory.  Implies --apply-acl.")
 	fileCmd.AddCommand(importCmd)
 
+	// file sync
+	syncCmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Synchronize the local directory tree into the repository.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return errors.New("specify a local directory and destination repository path")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			deleteFiles, _ := cmd.Flags().GetBool("delete")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			checksum, _ := cmd.Flags().GetBool("checksum")
+			options := &batch.SyncOptions{
+				Delete:   deleteFiles,
+				DryRun:   dryRun,
+				Checksum: checksum,
+				Includes: cmd.Flags().Lookup("include").Value.(*stringListFlag).values,
+				Excludes: cmd.Flags().Lookup("exclude").Value.(*stringListFlag).values,
+			}
+			bar := pb.StartNew(0)
+			changes, err := batch.SyncFiles(args[0], args[1], options, bar, &Client, Client.Logger)
+			bar.FinishPrint("Finished to synchronize the files.")
+			counts := map[string]int{}
+			for _, change := range changes {
+				status := ""
+				if change.Err != nil {
+					status = " (failed: " + change.Err.Error() + ")"
+				} else {
+					counts[change.Action]++
+				}
+				fmt.Printf("%-6s %s%s\n", change.Action, change.RemotePath, status)
+			}
+			summary := fmt.Sprintf("%d directories created, %d uploaded, %d updated, %d deleted.",
+				counts[batch.SyncActionCreateDirectory], counts[batch.SyncActionUpload], counts[batch.SyncActionUpdate], counts[batch.SyncActionDelete])
+			if dryRun {
+				summary = "(dry run) " + summary
+			}
+			fmt.Println(summary)
+			return err
+		},
+	}
+	syncCmd.Flags().Bool("delete", false, "Delete the files in the repository which are missing locally.")
+	syncCmd.Flags().BoolP("dry-run", "n", false, "Show what would be done without changing the repository.")
+	syncCmd.Flags().BoolP("checksum", "c", false, "Compare the content hash instead of the size and the date.")
+	syncCmd.Flags().VarP(newStringListFlag(), "include", "i", "The glob patterns of the files to synchronize. (e.g., --include '*.ktr,*.kjb')")
+	syncCmd.Flags().VarP(newStringListFlag(), "exclude", "x", "The glob patterns of the files/directories to skip. (e.g., --exclude '.git')")
+	fileCmd.AddCommand(syncCmd)
+
+	// file pull
+	pullCmd := &cobra.Command{
+		Use:   "pull",
+		Short: "Download the folder in the repository and extract it into the local directory.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return errors.New("specify a repository path and destination local directory")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			deleteFiles, _ := cmd.Flags().GetBool("delete")
+			raw, _ := cmd.Flags().GetBool("raw")
+			manifest, _ := cmd.Flags().GetString("manifest")
+			changes, err := batch.PullFiles(args[0], args[1], &batch.PullOptions{
+				Delete:      deleteFiles,
+				PrettyPrint: !raw,
+				Manifest:    manifest,
+			}, &Client, Client.Logger)
+			counts := map[string]int{}
+			for _, change := range changes {
+				counts[change.Action]++
+				fmt.Printf("%-6s %s\n", change.Action, change.LocalPath)
+			}
+			fmt.Printf("%d created, %d updated, %d deleted.\n", counts[batch.PullActionCreate], counts[batch.PullActionUpdate], counts[batch.PullActionDelete])
+			return err
+		},
+	}
+	pullCmd.Flags().Bool("delete", false, "Delete the local files which no longer exist in the repository.  The files starting with '.' are kept.")
+	pullCmd.Flags().Bool("raw", false, "Extract the XML content as is without pretty-printing.")
+	pullCmd.Flags().StringP("manifest", "m", "", "Save the export manifest to the file.")
+	fileCmd.AddCommand(pullCmd)
+
+	// file acl
+	fileCmd.AddCommand(newACLCommand())
+
+	// file mv/cp/rename
+	fileCmd.AddCommand(newMoveCommands()...)
+
+	// file trash
+	fileCmd.AddCommand(newTrashCommand())
+
+	// file versions/restore-version
+	fileCmd.AddCommand(newVersionCommands()...)
+
 	// file delete
 	var deleteCmd = &cobra.Command{
 		Use:   "delete",
@@ -190,8 +537,10 @@ func init() {
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			err := Client.DeleteFiles(args...)
-			return err
+			if permanent, _ := cmd.Flags().GetBool("permanent"); permanent {
+				return Client.DeleteFilesPermanently(args...)
+			}
+			return Client.DeleteFiles(args...)
 		},
 	}
 	deleteCmd.Flags().BoolP("permanent", "P", false, "Delete file permanently.")
@@ -227,3 +576,73 @@ func init() {
 		},
 	})
 }
+
+// formatFileDate formats the date of the file in the repository.
+func formatFileDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// fileRowHeader is the header of the table of the files.
+var fileRowHeader = []string{"Path", "Type", "Size", "Created", "Modified", "Locked", "Hidden", "Owner Type"}
+
+// fileRow creates a row of the table of the files.
+func fileRow(file *client.FileInfo) *[]string {
+	fileType := "file"
+	if file.IsFolder() {
+		fileType = "folder"
+	}
+	return &[]string{
+		file.Path,
+		fileType,
+		file.FileSize,
+		formatFileDate(file.Created()),
+		formatFileDate(file.Modified()),
+		file.Locked,
+		file.Hidden,
+		file.OwnerType,
+	}
+}
+
+func isImportLogLevel(level string) bool {
+	for _, l := range client.ImportLogLevels {
+		if strings.EqualFold(l, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// printImportResult prints the files in the import log.
+// It returns an error if some files are failed to import.
+func printImportResult(result *client.ImportResult) error {
+	for _, f := range result.Files {
+		path := f.Path
+		if path == "" {
+			path = "-"
+		}
+		if f.Message != "" && f.Status != client.ImportStatusImported {
+			fmt.Printf("%-8s %s (%s)\n", f.Status, path, f.Message)
+		} else {
+			fmt.Printf("%-8s %s\n", f.Status, path)
+		}
+	}
+	failed := len(result.Filter(client.ImportStatusFailed))
+	fmt.Printf("%d imported, %d skipped, %d failed.\n",
+		len(result.Filter(client.ImportStatusImported)), len(result.Filter(client.ImportStatusSkipped)), failed)
+	if failed > 0 {
+		return fmt.Errorf("failed to import %d files", failed)
+	}
+	return nil
+}
+
+// withProgress shows the progress bars of the file transfers in the function.
+func withProgress(f func() error) error {
+	Client.ShowProgress = true
+	defer func() {
+		Client.ShowProgress = false
+	}()
+	return f()
+}
@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/uphy/pentahotools/batch"
+	"github.com/uphy/pentahotools/client"
+	"github.com/uphy/pentahotools/table"
+	"gopkg.in/cheggaaa/pb.v1"
+)
+
+// newACLCommand creates 'file acl' command.
+func newACLCommand() *cobra.Command {
+	aclCmd := &cobra.Command{
+		Use:   "acl",
+		Short: "Manage the access control lists of the repository files.",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	// file acl show
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the access control list of the file.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("specify the repository path")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			recursive, _ := cmd.Flags().GetBool("recursive")
+			return walkACL(args[0], recursive, true, func(path string, acl *client.ACL) error {
+				printACL(path, acl)
+				return nil
+			})
+		},
+	}
+	showCmd.Flags().BoolP("recursive", "R", false, "Show the descendants which have their own entries.")
+	aclCmd.AddCommand(showCmd)
+
+	// file acl grant/revoke/set
+	for _, c := range []struct {
+		use                 string
+		short               string
+		permissionsOptional bool
+		f                   func(acl *client.ACL, recipient string, recipientType string, permissions []string)
+	}{
+		{"grant", "Grant the permissions to the user/role.", false, (*client.ACL).Grant},
+		{"revoke", "Revoke the permissions from the user/role.  All of the permissions are revoked if omitted.", true, (*client.ACL).Revoke},
+		{"set", "Replace the permissions of the user/role.", false, (*client.ACL).Set},
+	} {
+		f := c.f
+		permissionsOptional := c.permissionsOptional
+		changeCmd := &cobra.Command{
+			Use:   c.use,
+			Short: c.short,
+			Long:  c.short + "\nThe file stops inheriting to change the entries, and the inherited entries are kept as its own entries.",
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				if len(args) == 3 || (permissionsOptional && len(args) == 2) {
+					return nil
+				}
+				return errors.New("specify the repository path, the user/role and the permissions (read/write/delete/manage/all)")
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				var permissions []string
+				if len(args) == 3 {
+					var err error
+					if permissions, err = client.ParsePermissions(args[2]); err != nil {
+						return err
+					}
+				}
+				recipientType := client.RecipientTypeUser
+				if role, _ := cmd.Flags().GetBool("role"); role {
+					recipientType = client.RecipientTypeRole
+				}
+				recursive, _ := cmd.Flags().GetBool("recursive")
+				dryRun, _ := cmd.Flags().GetBool("dry-run")
+				return walkACL(args[0], recursive, true, func(path string, acl *client.ACL) error {
+					inheriting := acl.IsInheriting()
+					f(acl, args[1], recipientType, permissions)
+					if acl.IsInheriting() != inheriting {
+						// the entries are changed as the own entries of the file.
+						fmt.Printf("%s: inheriting=%s\n", path, acl.EntriesInheriting)
+					}
+					var result string
+					if ac := acl.Find(args[1], recipientType); ac != nil {
+						result = strings.Join(client.PermissionNames(ac.Permissions), ",")
+					} else {
+						result = "none"
+					}
+					fmt.Printf("%s: %s %s -> %s\n", path, client.RecipientTypeName(recipientType), args[1], result)
+					if dryRun {
+						return nil
+					}
+					return Client.SetACL(path, acl)
+				})
+			},
+		}
+		changeCmd.Flags().BoolP("role", "r", false, "The recipient is a role instead of a user.")
+		changeCmd.Flags().BoolP("recursive", "R", false, "Also change the descendants which have their own entries.")
+		changeCmd.Flags().BoolP("dry-run", "n", false, "Show what would be done without changing the repository.")
+		aclCmd.AddCommand(changeCmd)
+	}
+
+	// file acl inherit
+	inheritCmd := &cobra.Command{
+		Use:   "inherit",
+		Short: "Inherit the entries from the parent folder, or stop inheriting with --off.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("specify the repository path")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			off, _ := cmd.Flags().GetBool("off")
+			recursive, _ := cmd.Flags().GetBool("recursive")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			return walkACL(args[0], recursive, false, func(path string, acl *client.ACL) error {
+				if acl.IsInheriting() == !off {
+					return nil
+				}
+				acl.SetInheriting(!off)
+				fmt.Printf("%s: inheriting=%s\n", path, acl.EntriesInheriting)
+				if dryRun {
+					return nil
+				}
+				return Client.SetACL(path, acl)
+			})
+		},
+	}
+	inheritCmd.Flags().Bool("off", false, "Stop inheriting.  The current entries are kept as the own entries.")
+	inheritCmd.Flags().BoolP("recursive", "R", false, "Also change all of the descendants.")
+	inheritCmd.Flags().BoolP("dry-run", "n", false, "Show what would be done without changing the repository.")
+	aclCmd.AddCommand(inheritCmd)
+
+	// file acl export
+	aclCmd.AddCommand(&cobra.Command{
+		Use:   "export",
+		Short: "Export the ACLs of the folders to a YAML file.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return errors.New("specify the repository path and the output YAML file")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			count, err := batch.ExportACLs(args[0], args[1], &Client)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Exported %d ACLs to %s.\n", count, args[1])
+			return nil
+		},
+	})
+
+	// file acl apply
+	applyCmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Change the ACLs to the ones in the YAML file.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("specify the input YAML file")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			results, err := batch.ApplyACLs(args[0], &batch.ApplyACLsOptions{DryRun: dryRun}, &Client, Client.Logger)
+			changed := 0
+			for _, r := range results {
+				if r.Err != nil {
+					fmt.Printf("%s: failed: %s\n", r.Path, r.Err)
+					continue
+				}
+				if len(r.Changes) == 0 {
+					continue
+				}
+				changed++
+				fmt.Printf("%s:\n", r.Path)
+				for _, change := range r.Changes {
+					fmt.Printf("  %s\n", change)
+				}
+			}
+			summary := fmt.Sprintf("%d of %d ACLs changed.", changed, len(results))
+			if dryRun {
+				summary = "(dry run) " + summary
+			}
+			fmt.Println(summary)
+			return err
+		},
+	}
+	applyCmd.Flags().BoolP("dry-run", "n", false, "Show what would be done without changing the repository.")
+	aclCmd.AddCommand(applyCmd)
+
+	// file acl report
+	reportCmd := &cobra.Command{
+		Use:   "report",
+		Short: "Report the effective permissions of the folders.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("specify the repository path")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out, _ := cmd.Flags().GetString("out")
+			expandRoles, _ := cmd.Flags().GetBool("expand-roles")
+			includeFiles, _ := cmd.Flags().GetBool("files")
+			bar := pb.StartNew(0)
+			err := batch.ReportACLs(args[0], out, &batch.ReportACLsOptions{
+				ExpandRoles:  expandRoles,
+				IncludeFiles: includeFiles,
+			}, bar, &Client, Client.Logger)
+			bar.FinishPrint("Finished to report the permissions.")
+			return err
+		},
+	}
+	reportCmd.Flags().StringP("out", "o", table.ConsoleOutput, "Output file(csv/xlsx/html).")
+	reportCmd.Flags().BoolP("expand-roles", "e", false, "Add the rows for the users in the roles.")
+	reportCmd.Flags().BoolP("files", "f", false, "Include the files which have their own entries.")
+	aclCmd.AddCommand(reportCmd)
+
+	return aclCmd
+}
+
+// walkACL calls the function with the ACL of the path, and the descendants if recursive is true.
+// The descendants which inherit the entries are skipped if explicitOnly is true because they follow the ancestor.
+// The path itself is always passed.
+func walkACL(path string, recursive bool, explicitOnly bool, f func(path string, acl *client.ACL) error) error {
+	acl, err := Client.GetACL(path)
+	if err != nil {
+		return err
+	}
+	if err := f(path, acl); err != nil {
+		return err
+	}
+	if !recursive {
+		return nil
+	}
+	root, err := Client.Tree(path, -1, true)
+	if err != nil {
+		return err
+	}
+	return root.Walk(func(entry *client.FileEntry) error {
+		if entry == root {
+			return nil
+		}
+		acl, err := Client.GetACL(entry.File.Path)
+		if err != nil {
+			return err
+		}
+		if explicitOnly && acl.IsInheriting() {
+			return nil
+		}
+		return f(entry.File.Path, acl)
+	})
+}
+
+// printACL prints the access control list.
+func printACL(path string, acl *client.ACL) {
+	writer := client.NewIndentWriter(os.Stdout)
+	writer.Printf("%s\n", path)
+	writer.IncrementLevel()
+	writer.Printf("Owner     : %s (%s)\n", acl.Owner, client.RecipientTypeName(acl.OwnerType))
+	writer.Printf("Inheriting: %s\n", acl.EntriesInheriting)
+	writer.Printf("Entries:\n")
+	writer.IncrementLevel()
+	width := 0
+	for _, ac := range acl.Aces {
+		if len(ac.Recipient) > width {
+			width = len(ac.Recipient)
+		}
+	}
+	for _, ac := range acl.Aces {
+		writer.Printf("%-4s %-*s : %s\n", client.RecipientTypeName(ac.RecipientType), width, ac.Recipient, strings.Join(client.PermissionNames(ac.Permissions), ","))
+	}
+	writer.DecrementLevel()
+	writer.DecrementLevel()
+}
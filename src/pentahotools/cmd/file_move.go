@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/spf13/cobra"
+	"github.com/uphy/pentahotools/batch"
+)
+
+// newMoveCommands creates 'file mv', 'file cp' and 'file rename' commands.
+func newMoveCommands() []*cobra.Command {
+	var commands []*cobra.Command
+	for _, c := range []struct {
+		use     string
+		short   string
+		aliases []string
+		isCopy  bool
+	}{
+		{"mv", "Move the files/folders into the folder, or to the path.", []string{"move"}, false},
+		{"cp", "Copy the files/folders into the folder, or to the path.", []string{"copy"}, true},
+	} {
+		isCopy := c.isCopy
+		transferCmd := &cobra.Command{
+			Use:     c.use,
+			Short:   c.short,
+			Aliases: c.aliases,
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				if len(args) < 2 {
+					return errors.New("specify the source paths and the destination path")
+				}
+				return nil
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				overwrite, _ := cmd.Flags().GetBool("overwrite")
+				return transferFiles(args[:len(args)-1], args[len(args)-1], isCopy, overwrite)
+			},
+		}
+		transferCmd.Flags().BoolP("overwrite", "o", false, "Overwrite the existing files.  The overwritten files are moved to the trash unless copying into the folder.")
+		commands = append(commands, transferCmd)
+	}
+
+	renameCmd := &cobra.Command{
+		Use:   "rename",
+		Short: "Rename the file/folder.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return errors.New("specify the path and the new name")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			overwrite, _ := cmd.Flags().GetBool("overwrite")
+			target, err := batch.RenameFile(args[0], args[1], overwrite, &Client)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s -> %s\n", path.Clean(args[0]), target)
+			return nil
+		},
+	}
+	renameCmd.Flags().BoolP("overwrite", "o", false, "Overwrite the existing file.  The overwritten file is moved to the trash.")
+	return append(commands, renameCmd)
+}
+
+// transferFiles moves or copies the files, and prints the transferred files.
+func transferFiles(sources []string, destination string, isCopy bool, overwrite bool) error {
+	transfers, err := batch.TransferFiles(sources, destination, &batch.TransferOptions{
+		Copy:      isCopy,
+		Overwrite: overwrite,
+	}, &Client)
+	for _, t := range transfers {
+		fmt.Printf("%s -> %s\n", t.Source, t.Target)
+	}
+	return err
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/uphy/pentahotools/client"
+	"github.com/uphy/pentahotools/table"
+)
+
+// newTrashCommand creates 'file trash' command.
+func newTrashCommand() *cobra.Command {
+	trashCmd := &cobra.Command{
+		Use:   "trash",
+		Short: "Manage the deleted files in the trash.",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	// file trash list
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the files in the trash.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			files, err := Client.ListDeletedFiles()
+			if err != nil {
+				return err
+			}
+			out, _ := cmd.Flags().GetString("out")
+			writer, err := table.NewWriter(out, map[int]string{})
+			if err != nil {
+				return err
+			}
+			defer writer.Close()
+			writer.WriteHeader(&[]string{"ID", "Name", "Original Path", "Deleted", "Size"})
+			for _, file := range files {
+				writer.WriteRow(&[]string{file.ID, file.Name, file.OriginalPath(), formatFileDate(file.Deleted()), file.FileSize})
+			}
+			return nil
+		},
+	}
+	listCmd.Flags().StringP("out", "o", table.ConsoleOutput, "Output file(csv/xlsx/html).")
+	listCmd.Aliases = []string{"ls"}
+	trashCmd.AddCommand(listCmd)
+
+	// file trash restore
+	restoreCmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Restore the files in the trash specified by the names or IDs.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errors.New("specify the names or IDs of the deleted files")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			to, _ := cmd.Flags().GetString("to")
+			overwrite, _ := cmd.Flags().GetBool("overwrite")
+			files, err := Client.ListDeletedFiles()
+			if err != nil {
+				return err
+			}
+			var restoring []client.FileInfo
+			for _, nameOrID := range args {
+				file, err := findDeletedFile(files, nameOrID)
+				if err != nil {
+					return err
+				}
+				restoring = append(restoring, *file)
+			}
+			if to != "" && len(restoring) > 1 {
+				if info, err := Client.GetFileInfo(to); err != nil {
+					return err
+				} else if info == nil || !info.IsFolder() {
+					return errors.New("destination folder not found: " + to)
+				}
+			}
+			for _, file := range restoring {
+				if err := Client.RestoreFiles(file.ID); err != nil {
+					return err
+				}
+				fmt.Printf("%s restored to %s\n", file.ID, file.OriginalPath())
+				if to != "" {
+					if err := transferFiles([]string{file.OriginalPath()}, to, false, overwrite); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	}
+	restoreCmd.Flags().StringP("to", "t", "", "Move the restored files to the folder, or to the path.")
+	restoreCmd.Flags().BoolP("overwrite", "o", false, "Overwrite the existing files with --to.")
+	trashCmd.AddCommand(restoreCmd)
+
+	// file trash empty
+	emptyCmd := &cobra.Command{
+		Use:   "empty",
+		Short: "Delete the files in the trash permanently.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			olderThan, _ := cmd.Flags().GetString("older-than")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			var threshold time.Time
+			if olderThan != "" {
+				age, err := parseAge(olderThan)
+				if err != nil {
+					return err
+				}
+				threshold = time.Now().Add(-age)
+			}
+			files, err := Client.ListDeletedFiles()
+			if err != nil {
+				return err
+			}
+			var ids []string
+			for _, file := range selectDeletedFiles(files, threshold) {
+				ids = append(ids, file.ID)
+				fmt.Printf("delete %s (%s)\n", file.OriginalPath(), formatFileDate(file.Deleted()))
+			}
+			if len(ids) == 0 || dryRun {
+				fmt.Printf("%d files would be deleted.\n", len(ids))
+				return nil
+			}
+			if err := Client.DeleteDeletedFiles(ids...); err != nil {
+				return err
+			}
+			fmt.Printf("%d files deleted.\n", len(ids))
+			return nil
+		},
+	}
+	emptyCmd.Flags().String("older-than", "", "Delete only the files deleted before the age. (e.g., 30d, 12h)")
+	emptyCmd.Flags().BoolP("dry-run", "n", false, "Show what would be deleted.")
+	trashCmd.AddCommand(emptyCmd)
+
+	return trashCmd
+}
+
+// findDeletedFile finds the deleted file by the ID or the name.
+func findDeletedFile(files []client.FileInfo, nameOrID string) (*client.FileInfo, error) {
+	var found []client.FileInfo
+	for _, file := range files {
+		if file.ID == nameOrID {
+			return &file, nil
+		}
+		if file.Name == nameOrID || file.OriginalPath() == nameOrID {
+			found = append(found, file)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, errors.New("not found in the trash: " + nameOrID)
+	case 1:
+		return &found[0], nil
+	}
+	var ids []string
+	for _, file := range found {
+		ids = append(ids, fmt.Sprintf("%s (%s)", file.ID, file.OriginalPath()))
+	}
+	return nil, fmt.Errorf("multiple files named %s in the trash, specify the ID: %s", nameOrID, strings.Join(ids, ", "))
+}
+
+// selectDeletedFiles selects the files deleted before the threshold, or all of the files if the threshold is zero.
+// The files whose deleted date is unknown are not selected with the threshold.
+func selectDeletedFiles(files []client.FileInfo, threshold time.Time) []client.FileInfo {
+	if threshold.IsZero() {
+		return files
+	}
+	var selected []client.FileInfo
+	for _, file := range files {
+		if deleted := file.Deleted(); !deleted.IsZero() && deleted.Before(threshold) {
+			selected = append(selected, file)
+		}
+	}
+	return selected
+}
+
+// parseAge parses the age with the days unit in addition to time.Duration. (e.g., "30d", "12h")
+func parseAge(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil {
+			return 0, errors.New("invalid age: " + s)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	age, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, errors.New("invalid age: " + s)
+	}
+	return age, nil
+}
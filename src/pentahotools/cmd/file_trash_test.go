@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/uphy/pentahotools/client"
+)
+
+func deletedFile(id string, name string, folder string, deleted time.Time) client.FileInfo {
+	file := client.FileInfo{ID: id, Name: name, OriginalParentFolderPath: folder}
+	if !deleted.IsZero() {
+		file.DeletedDate = strconv.FormatInt(deleted.UnixNano()/int64(time.Millisecond), 10)
+	}
+	return file
+}
+
+func TestFindDeletedFile(t *testing.T) {
+	now := time.Now()
+	files := []client.FileInfo{
+		deletedFile("1", "a.ktr", "/public/dev", now),
+		deletedFile("2", "a.ktr", "/public/prod", now),
+		deletedFile("3", "b.ktr", "/public/dev", now),
+	}
+	for _, c := range []struct {
+		nameOrID string
+		expected string
+	}{
+		{"3", "3"},
+		{"b.ktr", "3"},
+		{"/public/prod/a.ktr", "2"},
+		{"2", "2"},
+		{"a.ktr", ""},
+		{"c.ktr", ""},
+	} {
+		file, err := findDeletedFile(files, c.nameOrID)
+		if c.expected == "" {
+			if err == nil {
+				t.Errorf("expected error for %s, got %v", c.nameOrID, file)
+			}
+			continue
+		}
+		if err != nil || file.ID != c.expected {
+			t.Errorf("expected %s for %s, got %v, %v", c.expected, c.nameOrID, file, err)
+		}
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	for s, expected := range map[string]time.Duration{
+		"30d":   30 * 24 * time.Hour,
+		"0d":    0,
+		"12h":   12 * time.Hour,
+		"1h30m": 90 * time.Minute,
+	} {
+		if age, err := parseAge(s); err != nil || age != expected {
+			t.Errorf("expected %v for %s, got %v, %v", expected, s, age, err)
+		}
+	}
+	for _, s := range []string{"", "d", "1.5d", "30", "abc"} {
+		if age, err := parseAge(s); err == nil {
+			t.Errorf("expected error for %q, got %v", s, age)
+		}
+	}
+}
+
+func TestSelectDeletedFiles(t *testing.T) {
+	now := time.Now()
+	files := []client.FileInfo{
+		deletedFile("old", "a.ktr", "/public", now.Add(-48*time.Hour)),
+		deletedFile("new", "b.ktr", "/public", now.Add(-time.Hour)),
+		deletedFile("unknown", "c.ktr", "/public", time.Time{}),
+	}
+	ids := func(files []client.FileInfo) []string {
+		var ids []string
+		for _, file := range files {
+			ids = append(ids, file.ID)
+		}
+		return ids
+	}
+	if actual := ids(selectDeletedFiles(files, now.Add(-24*time.Hour))); !reflect.DeepEqual(actual, []string{"old"}) {
+		t.Errorf("unexpected files: %v", actual)
+	}
+	if actual := ids(selectDeletedFiles(files, time.Time{})); !reflect.DeepEqual(actual, []string{"old", "new", "unknown"}) {
+		t.Errorf("all files should be selected without the threshold: %v", actual)
+	}
+}
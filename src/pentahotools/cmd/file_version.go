@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/uphy/pentahotools/table"
+)
+
+// newVersionCommands creates 'file versions' and 'file restore-version' commands.
+func newVersionCommands() []*cobra.Command {
+	// file versions
+	versionsCmd := &cobra.Command{
+		Use:   "versions",
+		Short: "List the versions of the file.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("specify the repository path")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			versions, err := Client.ListVersions(args[0])
+			if err != nil {
+				return err
+			}
+			out, _ := cmd.Flags().GetString("out")
+			writer, err := table.NewWriter(out, map[int]string{})
+			if err != nil {
+				return err
+			}
+			defer writer.Close()
+			writer.WriteHeader(&[]string{"Version", "Date", "Author", "Comment"})
+			for _, version := range versions {
+				writer.WriteRow(&[]string{version.ID, formatFileDate(version.Created()), version.Author, version.Message})
+			}
+			return nil
+		},
+	}
+	versionsCmd.Flags().StringP("out", "o", table.ConsoleOutput, "Output file(csv/xlsx/html).")
+
+	// file restore-version
+	restoreCmd := &cobra.Command{
+		Use:   "restore-version",
+		Short: "Restore the file to the previous version.  The restored content is saved as a new version.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return errors.New("specify the repository path and the version ID")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := Client.RestoreFileVersion(args[0], args[1]); err != nil {
+				return err
+			}
+			fmt.Printf("%s restored to the version %s\n", args[0], args[1])
+			return nil
+		},
+	}
+
+	return []*cobra.Command{versionsCmd, restoreCmd}
+}
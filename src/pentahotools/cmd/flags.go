@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/uphy/pentahotools/client"
+)
+
+// keyValueFlag is a repeatable flag holds 'name=value' pairs.
+// Setting an empty string clears the values, so that the flag can be reset in the multiple command mode.
+type keyValueFlag struct {
+	values map[string]string
+}
+
+func newKeyValueFlag() *keyValueFlag {
+	return &keyValueFlag{map[string]string{}}
+}
+
+func (f *keyValueFlag) String() string {
+	var pairs []string
+	for name, value := range f.values {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (f *keyValueFlag) Set(s string) error {
+	if s == "" {
+		f.values = map[string]string{}
+		return nil
+	}
+	i := strings.Index(s, "=")
+	if i <= 0 {
+		return fmt.Errorf("specify the value in 'name=value' format: %s", s)
+	}
+	f.values[s[:i]] = s[i+1:]
+	return nil
+}
+
+func (f *keyValueFlag) Type() string {
+	return "name=value"
+}
+
+// stringListFlag is a repeatable flag holds a list of strings.
+// The comma separated values are split.  Setting an empty string clears the values.
+type stringListFlag struct {
+	values []string
+}
+
+func newStringListFlag() *stringListFlag {
+	return &stringListFlag{}
+}
+
+func (f *stringListFlag) String() string {
+	return strings.Join(f.values, ",")
+}
+
+func (f *stringListFlag) Set(s string) error {
+	if s == "" {
+		f.values = nil
+		return nil
+	}
+	for _, value := range strings.Split(s, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			f.values = append(f.values, value)
+		}
+	}
+	return nil
+}
+
+func (f *stringListFlag) Type() string {
+	return "strings"
+}
+
+// jobParameterFlag is a repeatable flag holds 'name:type=value' typed parameters.
+// The type is optional and defaults to 'string'.  Repeating the same name makes a list parameter.
+type jobParameterFlag struct {
+	parameters []client.JobParameter
+}
+
+func newJobParameterFlag() *jobParameterFlag {
+	return &jobParameterFlag{}
+}
+
+func (f *jobParameterFlag) String() string {
+	var params []string
+	for _, p := range f.parameters {
+		params = append(params, fmt.Sprintf("%s:%s=%s", p.Name, p.Type, strings.Join(p.StringValue, "|")))
+	}
+	return strings.Join(params, ",")
+}
+
+func (f *jobParameterFlag) Set(s string) error {
+	if s == "" {
+		f.parameters = nil
+		return nil
+	}
+	i := strings.Index(s, "=")
+	if i <= 0 {
+		return fmt.Errorf("specify the parameter in 'name:type=value' format: %s", s)
+	}
+	name, value := s[:i], s[i+1:]
+	parameterType := "string"
+	if j := strings.LastIndex(name, ":"); j > 0 {
+		name, parameterType = name[:j], name[j+1:]
+	}
+	for k := range f.parameters {
+		p := &f.parameters[k]
+		if p.Name == name {
+			if p.Type != parameterType {
+				return fmt.Errorf("parameter '%s' is specified with different types", name)
+			}
+			p.StringValue = append(p.StringValue, value)
+			return p.Validate()
+		}
+	}
+	p := client.JobParameter{Name: name, Type: parameterType, StringValue: []string{value}}
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	f.parameters = append(f.parameters, p)
+	return nil
+}
+
+func (f *jobParameterFlag) Type() string {
+	return "name:type=value"
+}
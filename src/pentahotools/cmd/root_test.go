@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// TestFlagShorthands checks that the flags of the commands do not clash with the inherited flags.
+// cobra panics when the shorthand is redefined.
+func TestFlagShorthands(t *testing.T) {
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("%s: %v", cmd.CommandPath(), r)
+				}
+			}()
+			cmd.InheritedFlags()
+			cmd.LocalFlags()
+		}()
+		for _, c := range cmd.Commands() {
+			walk(c)
+		}
+	}
+	walk(RootCmd)
+}
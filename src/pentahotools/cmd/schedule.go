@@ -0,0 +1,616 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/uphy/pentahotools/batch"
+	"github.com/uphy/pentahotools/client"
+	"github.com/uphy/pentahotools/table"
+)
+
+func init() {
+	var scheduleCmd = &cobra.Command{
+		Use:   "schedule",
+		Short: "Scheduler management command",
+		Long:  `Manage the schedules of BA/DI server.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+	RootCmd.AddCommand(scheduleCmd)
+
+	// schedule list
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the scheduled jobs.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobs, err := Client.ListJobs()
+			if err != nil {
+				return err
+			}
+			out, _ := cmd.Flags().GetString("out")
+			writer, err := table.NewWriter(out, map[int]string{})
+			if err != nil {
+				return err
+			}
+			defer writer.Close()
+			writer.WriteHeader(&[]string{"ID", "Name", "Input File", "State", "Next Run", "Last Run", "Owner"})
+			for _, job := range jobs {
+				writer.WriteRow(&[]string{job.JobID, job.JobName, job.InputFile(), job.State, job.NextRun, job.LastRun, job.UserName})
+			}
+			return nil
+		},
+	}
+	listCmd.Flags().StringP("out", "o", table.ConsoleOutput, "Output file(csv/xlsx/html).")
+	listCmd.Aliases = []string{"ls"}
+	scheduleCmd.AddCommand(listCmd)
+
+	// schedule show
+	scheduleCmd.AddCommand(&cobra.Command{
+		Use:   "show",
+		Short: "Show the detail of the scheduled job.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("specify a job ID")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			job, err := Client.GetJobInfo(args[0])
+			if err != nil {
+				return err
+			}
+			writer := client.NewIndentWriter(os.Stdout)
+			writer.Printf("ID        : %s\n", job.JobID)
+			writer.Printf("Name      : %s\n", job.JobName)
+			writer.Printf("Input File: %s\n", job.InputFile())
+			writer.Printf("State     : %s\n", job.State)
+			writer.Printf("Trigger   : %s\n", job.JobTrigger.String())
+			writer.Printf("Next Run  : %s\n", job.NextRun)
+			writer.Printf("Last Run  : %s\n", job.LastRun)
+			writer.Printf("Owner     : %s\n", job.UserName)
+			var params, internalParams []client.JobParam
+			for _, param := range job.JobParams.JobParams {
+				if param.IsInternal() {
+					internalParams = append(internalParams, param)
+				} else {
+					params = append(params, param)
+				}
+			}
+			for _, p := range []struct {
+				title  string
+				params []client.JobParam
+			}{{"Parameters", params}, {"Scheduler Parameters", internalParams}} {
+				writer.Printf("%s:\n", p.title)
+				writer.IncrementLevel()
+				width := 0
+				for _, param := range p.params {
+					if len(param.Name) > width {
+						width = len(param.Name)
+					}
+				}
+				for _, param := range p.params {
+					writer.Printf("%-*s = %s\n", width, param.Name, param.Value)
+				}
+				writer.DecrementLevel()
+			}
+			return nil
+		},
+	})
+
+	// schedule create
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Schedule a job for the file in the repository.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("specify the input file in the repository")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, _ := cmd.Flags().GetString("name")
+			output, _ := cmd.Flags().GetString("output")
+			outputName, _ := cmd.Flags().GetString("output-name")
+			appendDate, _ := cmd.Flags().GetString("append-date")
+			format, _ := cmd.Flags().GetString("format")
+			// the generated content is named after the job.
+			if outputName != "" {
+				if name != "" && name != outputName {
+					return errors.New("--output-name is the name of the schedule.  can not specify both --name and --output-name")
+				}
+				name = outputName
+			}
+			isReport := client.IsReportFile(args[0])
+			if isReport {
+				if output == "" {
+					output = "/home/" + Client.User
+				}
+				if name == "" {
+					name = strings.TrimSuffix(path.Base(args[0]), path.Ext(args[0]))
+				}
+			} else if outputName != "" || appendDate != "" {
+				return errors.New("--output-name and --append-date are available for the reports(.prpt/.xanalyzer)")
+			}
+			parameters := cmd.Flags().Lookup("param").Value.(*jobParameterFlag).parameters
+			if format != "" {
+				outputTarget, ok := client.ReportOutputTargets[format]
+				if !ok || path.Ext(args[0]) != ".prpt" {
+					return errors.New("unsupported format: " + format)
+				}
+				parameters = append(parameters, client.JobParameter{Name: "output-target", Type: "string", StringValue: []string{outputTarget}})
+			}
+			req := &client.JobScheduleRequest{
+				JobName:          name,
+				InputFile:        args[0],
+				OutputFile:       output,
+				AppendDateFormat: appendDate,
+				JobParameters:    parameters,
+			}
+			if err := setScheduleTrigger(cmd, req); err != nil {
+				return err
+			}
+			jobID, err := Client.ScheduleJob(req)
+			if err != nil {
+				return err
+			}
+			fmt.Println(jobID)
+			return nil
+		},
+	}
+	createCmd.Flags().StringP("name", "n", "", "The name of the schedule.")
+	createCmd.Flags().StringP("output", "o", "", "The output folder of the generated content. (default the home folder for the reports)")
+	createCmd.Flags().String("output-name", "", "The file name of the generated content, which is also the name of the schedule. (default the name of the report)")
+	createCmd.Flags().String("append-date", "", "The date format appended to the file name of the generated content. (e.g., yyyy-MM-dd)")
+	createCmd.Flags().StringP("format", "f", "", "The output format of the report(.prpt). (pdf/html/xls/xlsx/csv/rtf/txt)")
+	createCmd.Flags().VarP(newJobParameterFlag(), "param", "P", "The parameter of the job.  Repeat the same name for a list. (e.g., -P region=east -P count:number=10 -P from:date=2017-10-01)")
+	addScheduleTriggerFlags(createCmd)
+	scheduleCmd.AddCommand(createCmd)
+
+	// schedule outputs
+	outputsCmd := &cobra.Command{
+		Use:   "outputs",
+		Short: "List the content generated by the scheduled job.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("specify a job ID")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			job, err := Client.GetJobInfo(args[0])
+			if err != nil {
+				return err
+			}
+			if job.OutputFile() == "" {
+				return fmt.Errorf("the job does not generate content: %s", job.JobName)
+			}
+			files, err := Client.GetGeneratedContent(job.InputFile())
+			if err != nil {
+				return err
+			}
+			var outputs []client.FileInfo
+			for _, file := range files {
+				if job.IsOutput(&file) {
+					outputs = append(outputs, file)
+				}
+			}
+			sort.Slice(outputs, func(i, j int) bool {
+				return outputs[i].Created().After(outputs[j].Created())
+			})
+			if latest, _ := cmd.Flags().GetBool("latest"); latest && len(outputs) > 1 {
+				outputs = outputs[:1]
+			}
+			if dir, _ := cmd.Flags().GetString("download"); dir != "" {
+				overwrite, _ := cmd.Flags().GetBool("overwrite")
+				for _, file := range outputs {
+					saved, err := Client.DownloadFile(file.Path, path.Join(dir, file.Name), false, overwrite)
+					if err != nil {
+						return err
+					}
+					fmt.Println("Saved file to " + saved)
+				}
+				return nil
+			}
+			if len(outputs) == 0 {
+				fmt.Printf("No content generated in %s.\n", job.OutputFolder())
+				return nil
+			}
+			out, _ := cmd.Flags().GetString("out")
+			writer, err := table.NewWriter(out, map[int]string{})
+			if err != nil {
+				return err
+			}
+			defer writer.Close()
+			writer.WriteHeader(&[]string{"Path", "Created", "Size"})
+			for _, file := range outputs {
+				writer.WriteRow(&[]string{file.Path, formatFileDate(file.Created()), file.FileSize})
+			}
+			return nil
+		},
+	}
+	outputsCmd.Flags().StringP("out", "o", table.ConsoleOutput, "Output file(csv/xlsx/html).")
+	outputsCmd.Flags().StringP("download", "d", "", "Download the generated content to the directory.")
+	outputsCmd.Flags().Bool("latest", false, "Only the latest content.")
+	outputsCmd.Flags().Bool("overwrite", false, "Overwrite the downloaded files if exist.")
+	scheduleCmd.AddCommand(outputsCmd)
+
+	// schedule delete
+	deleteCmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete the scheduled jobs.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errors.New("specify the job IDs")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, jobID := range args {
+				if err := Client.RemoveJob(jobID); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	deleteCmd.Aliases = []string{"rm"}
+	scheduleCmd.AddCommand(deleteCmd)
+
+	// schedule export
+	scheduleCmd.AddCommand(&cobra.Command{
+		Use:   "export",
+		Short: "Export all of the schedules to a YAML file.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("specify the output YAML file")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			count, err := batch.ExportSchedules(args[0], &Client)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Exported %d schedules to %s.\n", count, args[0])
+			return nil
+		},
+	})
+
+	// schedule import
+	importCmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import the schedules from a YAML file.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("specify the input YAML file")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			replace, _ := cmd.Flags().GetBool("replace")
+			results, err := batch.ImportSchedules(args[0], &batch.ImportSchedulesOptions{
+				DryRun:      dryRun,
+				Replace:     replace,
+				PathMapping: cmd.Flags().Lookup("map").Value.(*keyValueFlag).values,
+			}, &Client, Client.Logger)
+			for _, r := range results {
+				status := r.Action
+				if r.Err != nil {
+					status = "failed: " + r.Err.Error()
+				}
+				if r.Owner != "" && r.Owner != Client.User {
+					status += fmt.Sprintf(" (owner %s -> %s)", r.Owner, Client.User)
+				}
+				fmt.Printf("%s (%s): %s\n", r.Name, r.InputFile, status)
+			}
+			return err
+		},
+	}
+	importCmd.Flags().BoolP("dry-run", "n", false, "Show what would be done without changing the server.")
+	importCmd.Flags().BoolP("replace", "r", false, "Replace the existing schedules which have the same name and input file.")
+	importCmd.Flags().VarP(newKeyValueFlag(), "map", "m", "Rewrite the path prefix. (e.g., --map /public/dev=/public/prod)")
+	scheduleCmd.AddCommand(importCmd)
+
+	// schedule blockout
+	blockoutCmd := &cobra.Command{
+		Use:   "blockout",
+		Short: "Manage the blockout windows.",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+	scheduleCmd.AddCommand(blockoutCmd)
+
+	// schedule blockout list
+	blockoutListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the blockout windows.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			blockouts, err := Client.ListBlockouts()
+			if err != nil {
+				return err
+			}
+			out, _ := cmd.Flags().GetString("out")
+			writer, err := table.NewWriter(out, map[int]string{})
+			if err != nil {
+				return err
+			}
+			defer writer.Close()
+			writer.WriteHeader(&[]string{"ID", "Trigger", "Duration", "Start", "End", "Next Run"})
+			for _, blockout := range blockouts {
+				trigger := blockout.JobTrigger
+				writer.WriteRow(&[]string{blockout.JobID, trigger.String(), blockout.BlockoutDuration().String(), trigger.StartTime, trigger.EndTime, blockout.NextRun})
+			}
+			return nil
+		},
+	}
+	blockoutListCmd.Flags().StringP("out", "o", table.ConsoleOutput, "Output file(csv/xlsx/html).")
+	blockoutListCmd.Aliases = []string{"ls"}
+	blockoutCmd.AddCommand(blockoutListCmd)
+
+	// schedule blockout add/check
+	for _, c := range []struct {
+		use   string
+		short string
+		add   bool
+	}{
+		{"add", "Add a blockout window.", true},
+		{"check", "Show the schedules which would run in the blockout window.", false},
+	} {
+		add := c.add
+		blockoutAddCmd := &cobra.Command{
+			Use:   c.use,
+			Short: c.short,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				duration, _ := cmd.Flags().GetDuration("duration")
+				if duration <= 0 {
+					return errors.New("specify the duration of the blockout window. (e.g., --duration 2h)")
+				}
+				req := &client.JobScheduleRequest{
+					Duration: int64(duration / time.Millisecond),
+				}
+				if zone := time.Local.String(); zone != "Local" {
+					req.TimeZone = zone
+				}
+				if err := setScheduleTrigger(cmd, req); err != nil {
+					return err
+				}
+				days, _ := cmd.Flags().GetInt("days")
+				if err := printBlockoutConflicts(req.Trigger(), duration, days); err != nil {
+					return err
+				}
+				if !add {
+					return nil
+				}
+				jobID, err := Client.AddBlockout(req)
+				if err != nil {
+					return err
+				}
+				fmt.Println(jobID)
+				return nil
+			},
+		}
+		blockoutAddCmd.Flags().DurationP("duration", "d", 0, "The duration of the blockout window. (e.g., 2h, 30m)")
+		blockoutAddCmd.Flags().Int("days", 7, "The number of days to check the schedules in the blockout window.")
+		addScheduleTriggerFlags(blockoutAddCmd)
+		blockoutCmd.AddCommand(blockoutAddCmd)
+	}
+
+	// schedule blockout remove
+	blockoutRemoveCmd := &cobra.Command{
+		Use:   "remove",
+		Short: "Remove the blockout windows.",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errors.New("specify the blockout IDs")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, jobID := range args {
+				if err := Client.RemoveBlockout(jobID); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	blockoutRemoveCmd.Aliases = []string{"rm"}
+	blockoutCmd.AddCommand(blockoutRemoveCmd)
+
+	// schedule pause/resume/run-now
+	for _, c := range []struct {
+		use   string
+		short string
+		f     func(string) (string, error)
+	}{
+		{"pause", "Pause the scheduled jobs.", func(jobID string) (string, error) { return Client.PauseJob(jobID) }},
+		{"resume", "Resume the paused jobs.", func(jobID string) (string, error) { return Client.ResumeJob(jobID) }},
+		{"run-now", "Run the scheduled jobs immediately.", func(jobID string) (string, error) { return Client.TriggerJobNow(jobID) }},
+	} {
+		f := c.f
+		scheduleCmd.AddCommand(&cobra.Command{
+			Use:   c.use,
+			Short: c.short,
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				if len(args) == 0 {
+					return errors.New("specify the job IDs")
+				}
+				return nil
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				for _, jobID := range args {
+					state, err := f(jobID)
+					if err != nil {
+						return err
+					}
+					fmt.Printf("%s: %s\n", jobID, state)
+				}
+				return nil
+			},
+		})
+	}
+
+	// schedule pause-all/resume-all/state
+	for _, c := range []struct {
+		use   string
+		short string
+		f     func() (string, error)
+	}{
+		{"pause-all", "Pause the scheduler.", func() (string, error) { return Client.PauseScheduler() }},
+		{"resume-all", "Resume the scheduler.", func() (string, error) { return Client.StartScheduler() }},
+		{"state", "Show the state of the scheduler.", func() (string, error) { return Client.GetSchedulerState() }},
+	} {
+		f := c.f
+		scheduleCmd.AddCommand(&cobra.Command{
+			Use:   c.use,
+			Short: c.short,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				state, err := f()
+				if err != nil {
+					return err
+				}
+				fmt.Println(state)
+				return nil
+			},
+		})
+	}
+}
+
+// parseScheduleTime parses the time specified in the command line to the format of the scheduler API.
+func parseScheduleTime(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t.Format(client.ScheduleTimeFormat), nil
+		}
+	}
+	return "", errors.New("invalid time: " + s)
+}
+
+// addScheduleTriggerFlags adds the flags to specify the trigger of the schedule.
+func addScheduleTriggerFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("start", "s", "", "The start time. (e.g., 2017-10-25T02:30:00) (default now)")
+	cmd.Flags().StringP("end", "e", "", "The end time. (e.g., 2017-12-31T23:59:59)")
+	cmd.Flags().IntP("interval", "i", 0, "The repeat interval in seconds.")
+	cmd.Flags().IntP("count", "c", 0, "The repeat count. (-1 means forever)")
+	cmd.Flags().String("cron", "", "The Quartz cron expression. (e.g., '0 30 2 ? * MON-FRI')")
+	cmd.Flags().String("days-of-week", "", "The days of week. (e.g., MON-FRI)")
+	cmd.Flags().String("weeks-of-month", "", "The weeks of month used with --days-of-week. (e.g., 1,LAST)")
+	cmd.Flags().String("days-of-month", "", "The days of month. (e.g., 1,15)")
+	cmd.Flags().String("months", "", "The months. (e.g., JAN,APR,JUL,OCT)")
+	cmd.Flags().String("at", "00:00", "The time of day used with the days/months flags. (e.g., 02:30)")
+}
+
+// setScheduleTrigger sets the trigger specified by the flags added by addScheduleTriggerFlags.
+// --cron makes a cron trigger, the days/months flags make a complex trigger, otherwise a simple trigger.
+func setScheduleTrigger(cmd *cobra.Command, req *client.JobScheduleRequest) error {
+	start, _ := cmd.Flags().GetString("start")
+	end, _ := cmd.Flags().GetString("end")
+	startTime, err := parseScheduleTime(start)
+	if err != nil {
+		return err
+	}
+	if startTime == "" {
+		startTime = time.Now().Format(client.ScheduleTimeFormat)
+	}
+	endTime, err := parseScheduleTime(end)
+	if err != nil {
+		return err
+	}
+	if cron, _ := cmd.Flags().GetString("cron"); cron != "" {
+		req.CronJobTrigger = &client.CronJobTrigger{
+			UIPassParam: "CRON",
+			CronString:  cron,
+			StartTime:   startTime,
+			EndTime:     endTime,
+		}
+		return nil
+	}
+	daysOfWeek, _ := cmd.Flags().GetString("days-of-week")
+	weeksOfMonth, _ := cmd.Flags().GetString("weeks-of-month")
+	daysOfMonth, _ := cmd.Flags().GetString("days-of-month")
+	months, _ := cmd.Flags().GetString("months")
+	if daysOfWeek != "" || weeksOfMonth != "" || daysOfMonth != "" || months != "" {
+		trigger := &client.ComplexJobTrigger{StartTime: startTime, EndTime: endTime}
+		if trigger.DaysOfWeek, err = client.ParseDaysOfWeek(daysOfWeek); err != nil {
+			return err
+		}
+		if trigger.WeeksOfMonth, err = client.ParseWeeksOfMonth(weeksOfMonth); err != nil {
+			return err
+		}
+		if trigger.DaysOfMonth, err = client.ParseDaysOfMonth(daysOfMonth); err != nil {
+			return err
+		}
+		if trigger.MonthsOfYear, err = client.ParseMonthsOfYear(months); err != nil {
+			return err
+		}
+		if len(trigger.WeeksOfMonth) > 0 && len(trigger.DaysOfWeek) == 0 {
+			return errors.New("specify --days-of-week with --weeks-of-month")
+		}
+		if len(trigger.DaysOfWeek) > 0 && len(trigger.DaysOfMonth) > 0 {
+			return errors.New("can not specify both --days-of-week and --days-of-month")
+		}
+		at, _ := cmd.Flags().GetString("at")
+		t, err := time.Parse("15:04", at)
+		if err != nil {
+			return errors.New("invalid time of day: " + at)
+		}
+		trigger.HourOfDay, trigger.MinuteOfHour = t.Hour(), t.Minute()
+		switch {
+		case len(trigger.MonthsOfYear) > 0:
+			trigger.UIPassParam = "YEARLY"
+		case len(trigger.DaysOfMonth) > 0 || len(trigger.WeeksOfMonth) > 0:
+			trigger.UIPassParam = "MONTHLY"
+		default:
+			trigger.UIPassParam = "WEEKLY"
+		}
+		req.ComplexJobTrigger = trigger
+		return nil
+	}
+	interval, _ := cmd.Flags().GetInt("interval")
+	count, _ := cmd.Flags().GetInt("count")
+	req.SimpleJobTrigger = &client.SimpleJobTrigger{
+		RepeatInterval: interval,
+		RepeatCount:    count,
+		StartTime:      startTime,
+		EndTime:        endTime,
+	}
+	return nil
+}
+
+// printBlockoutConflicts prints the schedules which would run in the blockout window within the days from now.
+func printBlockoutConflicts(trigger *client.JobTrigger, duration time.Duration, days int) error {
+	from := time.Now()
+	to := from.AddDate(0, 0, days)
+	windows, err := client.BlockoutWindows(trigger, duration, from, to)
+	if err != nil {
+		return err
+	}
+	jobs, err := Client.ListJobs()
+	if err != nil {
+		return err
+	}
+	conflicts := client.FindBlockoutConflicts(jobs, windows, from, to, Client.Logger)
+	if len(conflicts) == 0 {
+		fmt.Printf("No schedules run in the blockout windows within %d days.\n", days)
+		return nil
+	}
+	fmt.Printf("%d runs are in the blockout windows within %d days:\n", len(conflicts), days)
+	const layout = "2006-01-02 15:04"
+	for _, c := range conflicts {
+		fmt.Printf("  %s %s (%s) in [%s - %s]\n", c.FireTime.Format(layout), c.Job.JobName, c.Job.JobID, c.Window.Start.Format(layout), c.Window.End.Format(layout))
+	}
+	return nil
+}
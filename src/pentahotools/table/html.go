@@ -0,0 +1,70 @@
+package table
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+const htmlHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="UTF-8">
+<style>
+table { border-collapse: collapse; }
+th, td { border: 1px solid #999; padding: 2px 6px; font-family: monospace; white-space: pre; }
+th { background: #eee; }
+</style>
+</head>
+<body>
+<table>
+`
+
+const htmlFooter = `</table>
+</body>
+</html>
+`
+
+type htmlTableWriter struct {
+	file   *os.File
+	writer *bufio.Writer
+}
+
+func newHTMLTableWriter(file string) (Writer, error) {
+	f, err := os.Create(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "html file open failed")
+	}
+	writer := bufio.NewWriter(f)
+	writer.WriteString(htmlHeader)
+	return &htmlTableWriter{f, writer}, nil
+}
+
+func (h *htmlTableWriter) WriteHeader(row *[]string) error {
+	return h.writeRow(row, "th")
+}
+
+func (h *htmlTableWriter) WriteRow(row *[]string) error {
+	return h.writeRow(row, "td")
+}
+
+func (h *htmlTableWriter) writeRow(row *[]string, tag string) error {
+	h.writer.WriteString("<tr>")
+	for _, s := range *row {
+		fmt.Fprintf(h.writer, "<%s>%s</%s>", tag, html.EscapeString(s), tag)
+	}
+	_, err := h.writer.WriteString("</tr>\n")
+	return err
+}
+
+func (h *htmlTableWriter) Close() error {
+	h.writer.WriteString(htmlFooter)
+	if err := h.writer.Flush(); err != nil {
+		h.file.Close()
+		return err
+	}
+	return h.file.Close()
+}
@@ -54,6 +54,8 @@ func NewWriter(file string, options map[int]string) (Writer, error) {
 	case ".csv":
 		separator := options[CsvSeparator]
 		return newCsvTableWriter(file, separator)
+	case ".html", ".htm":
+		return newHTMLTableWriter(file)
 	}
 	return nil, errors.New("unsupported file: " + file)
 }